@@ -3,7 +3,11 @@ module github.com/PFrek/gorss
 go 1.22.3
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/andybalholm/brotli v1.1.1
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
+	golang.org/x/net v0.26.0
 )
+
+require golang.org/x/text v0.16.0 // indirect
@@ -6,10 +6,12 @@ import (
 	"net/http"
 
 	"github.com/PFrek/gorss/internal/database"
+	"github.com/PFrek/gorss/internal/scraper"
 )
 
 type ApiConfig struct {
-	DB *database.Queries
+	DB      *database.Queries
+	Scraper *scraper.Scraper
 }
 
 func extractBody(req *http.Request, v any) error {
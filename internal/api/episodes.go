@@ -0,0 +1,167 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+type ResponseEpisode struct {
+	EnclosureID     uuid.UUID `json:"enclosure_id"`
+	Url             string    `json:"url"`
+	Type            string    `json:"type"`
+	Length          *int64    `json:"length"`
+	PostID          uuid.UUID `json:"post_id"`
+	Title           string    `json:"title"`
+	PublishedAt     time.Time `json:"published_at"`
+	FeedID          uuid.UUID `json:"feed_id"`
+	DurationSeconds *int32    `json:"duration_seconds"`
+	Episode         *int32    `json:"episode"`
+	Season          *int32    `json:"season"`
+	ImageUrl        *string   `json:"image_url"`
+	Explicit        *bool     `json:"explicit"`
+	PositionSeconds int32     `json:"position_seconds"`
+	Completed       bool      `json:"completed"`
+}
+
+type ResponsePlaybackPosition struct {
+	EnclosureID     uuid.UUID `json:"enclosure_id"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	PositionSeconds int32     `json:"position_seconds"`
+	Completed       bool      `json:"completed"`
+}
+
+func nullInt32ToPtr(value sql.NullInt32) *int32 {
+	if !value.Valid {
+		return nil
+	}
+
+	result := new(int32)
+	*result = value.Int32
+	return result
+}
+
+func nullBoolToPtr(value sql.NullBool) *bool {
+	if !value.Valid {
+		return nil
+	}
+
+	result := new(bool)
+	*result = value.Bool
+	return result
+}
+
+func episodeFromDBEpisode(episode database.GetEpisodesByUserRow) ResponseEpisode {
+	var length *int64
+	if episode.Length.Valid {
+		length = new(int64)
+		*length = episode.Length.Int64
+	}
+
+	return ResponseEpisode{
+		EnclosureID:     episode.EnclosureID,
+		Url:             episode.Url,
+		Type:            episode.Type.String,
+		Length:          length,
+		PostID:          episode.PostID,
+		Title:           episode.Title,
+		PublishedAt:     episode.PublishedAt,
+		FeedID:          episode.FeedID,
+		DurationSeconds: nullInt32ToPtr(episode.DurationSeconds),
+		Episode:         nullInt32ToPtr(episode.Episode),
+		Season:          nullInt32ToPtr(episode.Season),
+		ImageUrl:        nullStringToPtr(episode.ImageUrl),
+		Explicit:        nullBoolToPtr(episode.Explicit),
+		PositionSeconds: episode.PositionSeconds.Int32,
+		Completed:       episode.Completed.Bool,
+	}
+}
+
+func playbackPositionFromDBPlaybackPosition(position database.PlaybackPosition) ResponsePlaybackPosition {
+	return ResponsePlaybackPosition{
+		EnclosureID:     position.EnclosureID,
+		UpdatedAt:       position.UpdatedAt,
+		PositionSeconds: position.PositionSeconds,
+		Completed:       position.Completed,
+	}
+}
+
+// GetEpisodesHandler lists the audio and video enclosures of the user's
+// followed feeds, newest first, along with the user's playback position.
+func (config *ApiConfig) GetEpisodesHandler(w http.ResponseWriter, req *http.Request, user database.User) {
+	limitQuery, err := extractQuery(req, "limit")
+	if err != nil {
+		limitQuery = "10"
+	}
+
+	limit, err := strconv.Atoi(limitQuery)
+	if err != nil {
+		limit = 10
+	}
+
+	episodes, err := config.DB.GetEpisodesByUser(req.Context(), database.GetEpisodesByUserParams{
+		UserID: user.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Failed to get episodes")
+		return
+	}
+
+	response := []ResponseEpisode{}
+	for _, episode := range episodes {
+		response = append(response, episodeFromDBEpisode(episode))
+	}
+
+	respondWithJSON(w, 200, response)
+}
+
+func (config *ApiConfig) PutPlaybackPositionHandler(w http.ResponseWriter, req *http.Request, user database.User) {
+	type parameters struct {
+		PositionSeconds int32 `json:"position_seconds"`
+		Completed       bool  `json:"completed"`
+	}
+
+	idStr := req.PathValue("enclosureID")
+	enclosureID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondWithError(w, 400, "Invalid Enclosure ID")
+		return
+	}
+
+	reqBody := parameters{}
+	err = extractBody(req, &reqBody)
+	if err != nil || reqBody.PositionSeconds < 0 {
+		respondWithError(w, 400, "Invalid request body")
+		return
+	}
+
+	ctx := req.Context()
+
+	_, err = config.DB.GetPostEnclosureByUser(ctx, database.GetPostEnclosureByUserParams{
+		ID:     enclosureID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Enclosure Not Found")
+		return
+	}
+
+	position, err := config.DB.UpsertPlaybackPosition(ctx, database.UpsertPlaybackPositionParams{
+		UserID:          user.ID,
+		EnclosureID:     enclosureID,
+		UpdatedAt:       time.Now().UTC(),
+		PositionSeconds: reqBody.PositionSeconds,
+		Completed:       reqBody.Completed,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Failed to save playback position")
+		return
+	}
+
+	respondWithJSON(w, 200, playbackPositionFromDBPlaybackPosition(position))
+}
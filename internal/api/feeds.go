@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/PFrek/gorss/internal/database"
+	"github.com/PFrek/gorss/internal/scraper"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
@@ -18,6 +19,10 @@ type ResponseFeed struct {
 	Url           string     `json:"url"`
 	UserID        uuid.UUID  `json:"user_id"`
 	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	FailureCount  int32      `json:"failure_count"`
+	LastError     *string    `json:"last_error"`
+	LastErrorAt   *time.Time `json:"last_error_at"`
+	Dead          bool       `json:"dead"`
 }
 
 func feedFromDBFeed(feed database.Feed) ResponseFeed {
@@ -26,6 +31,19 @@ func feedFromDBFeed(feed database.Feed) ResponseFeed {
 		lastFetch = new(time.Time)
 		*lastFetch = feed.LastFetchedAt.Time
 	}
+
+	var lastError *string
+	if feed.LastError.Valid {
+		lastError = new(string)
+		*lastError = feed.LastError.String
+	}
+
+	var lastErrorAt *time.Time
+	if feed.LastErrorAt.Valid {
+		lastErrorAt = new(time.Time)
+		*lastErrorAt = feed.LastErrorAt.Time
+	}
+
 	return ResponseFeed{
 		ID:            feed.ID,
 		CreatedAt:     feed.CreatedAt,
@@ -34,6 +52,10 @@ func feedFromDBFeed(feed database.Feed) ResponseFeed {
 		Url:           feed.Url,
 		UserID:        feed.UserID,
 		LastFetchedAt: lastFetch,
+		FailureCount:  feed.FailureCount,
+		LastError:     lastError,
+		LastErrorAt:   lastErrorAt,
+		Dead:          feed.Dead,
 	}
 }
 
@@ -45,19 +67,69 @@ func (config *ApiConfig) PostFeedsHandler(w http.ResponseWriter, req *http.Reque
 
 	reqBody := parameters{}
 	err := extractBody(req, &reqBody)
-	if err != nil || reqBody.Name == "" || reqBody.Url == "" {
+	if err != nil || reqBody.Url == "" {
 		respondWithError(w, 400, "Invalid request body")
 		return
 	}
 
 	ctx := req.Context()
+
+	candidates, err := config.Scraper.Discover(ctx, reqBody.Url)
+	if err != nil {
+		respondWithError(w, 422, fmt.Sprintf("Failed to fetch URL: %v", err))
+		return
+	}
+
+	if len(candidates) == 0 {
+		respondWithError(w, 422, "No feed found at URL")
+		return
+	}
+
+	if len(candidates) > 1 {
+		response := struct {
+			Candidates []scraper.DiscoveredFeed `json:"candidates"`
+		}{
+			Candidates: candidates,
+		}
+		respondWithJSON(w, 300, response)
+		return
+	}
+
+	feedUrl := candidates[0].Url
+
+	// Pages advertising a single feed only yield its URL, so the feed itself
+	// still has to be fetched to validate it.
+	fetched := candidates[0].Feed
+	if fetched == nil {
+		feedData, err := config.Scraper.FetchFeedData(ctx, feedUrl)
+		if err != nil {
+			respondWithError(w, 422, fmt.Sprintf("URL is not a valid feed: %v", err))
+			return
+		}
+		fetched = &feedData
+	}
+
+	_, err = config.DB.GetFeedByUrl(ctx, feedUrl)
+	if err == nil {
+		respondWithError(w, 400, "URL already registered")
+		return
+	}
+
+	name := reqBody.Name
+	if name == "" {
+		name = fetched.Data.Title
+	}
+	if name == "" {
+		name = feedUrl
+	}
+
 	currentTime := time.Now().UTC()
 	feed, err := config.DB.CreateFeed(ctx, database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: currentTime,
 		UpdatedAt: currentTime,
-		Name:      reqBody.Name,
-		Url:       reqBody.Url,
+		Name:      name,
+		Url:       feedUrl,
 		UserID:    user.ID,
 	})
 	if err != nil {
@@ -71,6 +143,8 @@ func (config *ApiConfig) PostFeedsHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
+	config.Scraper.IngestFeed(feed, *fetched)
+
 	responseFeed := feedFromDBFeed(feed)
 
 	feedFollow, err := config.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
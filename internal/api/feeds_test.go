@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/PFrek/gorss/internal/scraper"
+)
+
+func TestPostFeedsHandlerCandidates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head>
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="/rss.xml">
+	<link rel="alternate" type="application/atom+xml" title="Atom" href="/atom.xml">
+</head></html>`))
+	})
+	mux.HandleFunc("/single/{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head>
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="/broken.xml">
+</head></html>`))
+	})
+	mux.HandleFunc("/broken.xml", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`not a feed`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := ApiConfig{
+		Scraper: &scraper.Scraper{},
+	}
+
+	cases := []struct {
+		url          string
+		expectedCode int
+	}{
+		{server.URL + "/", 300},
+		{server.URL + "/single/", 422},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/v1/feeds", strings.NewReader(`{"url": "`+c.url+`"}`))
+		w := httptest.NewRecorder()
+
+		config.PostFeedsHandler(w, req, database.User{})
+
+		if w.Code != c.expectedCode {
+			t.Fatalf("%s: expected status %d got %d: %s", c.url, c.expectedCode, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+type ResponseFetchAttempt struct {
+	ID            uuid.UUID `json:"id"`
+	FeedID        uuid.UUID `json:"feed_id"`
+	AttemptedAt   time.Time `json:"attempted_at"`
+	StatusCode    *int32    `json:"status_code"`
+	DurationMs    int32     `json:"duration_ms"`
+	Bytes         int32     `json:"bytes"`
+	ItemsFound    int32     `json:"items_found"`
+	ItemsInserted int32     `json:"items_inserted"`
+	Error         *string   `json:"error"`
+	Recoveries    []string  `json:"recoveries"`
+}
+
+func fetchAttemptFromDBFetchAttempt(attempt database.FetchAttempt) ResponseFetchAttempt {
+	var statusCode *int32
+	if attempt.StatusCode.Valid {
+		statusCode = new(int32)
+		*statusCode = attempt.StatusCode.Int32
+	}
+
+	var fetchError *string
+	if attempt.Error.Valid {
+		fetchError = new(string)
+		*fetchError = attempt.Error.String
+	}
+
+	return ResponseFetchAttempt{
+		ID:            attempt.ID,
+		FeedID:        attempt.FeedID,
+		AttemptedAt:   attempt.AttemptedAt,
+		StatusCode:    statusCode,
+		DurationMs:    attempt.DurationMs,
+		Bytes:         attempt.Bytes,
+		ItemsFound:    attempt.ItemsFound,
+		ItemsInserted: attempt.ItemsInserted,
+		Error:         fetchError,
+		Recoveries:    attempt.Recoveries,
+	}
+}
+
+func (config *ApiConfig) GetFeedHistoryHandler(w http.ResponseWriter, req *http.Request) {
+	idStr := req.PathValue("feedID")
+	feedID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondWithError(w, 400, "Invalid Feed ID")
+		return
+	}
+
+	limitQuery, err := extractQuery(req, "limit")
+	if err != nil {
+		limitQuery = "20"
+	}
+
+	limit, err := strconv.Atoi(limitQuery)
+	if err != nil {
+		limit = 20
+	}
+
+	ctx := req.Context()
+
+	_, err = config.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		respondWithError(w, 404, "Feed Not Found")
+		return
+	}
+
+	attempts, err := config.DB.GetFetchAttempts(ctx, database.GetFetchAttemptsParams{
+		FeedID: feedID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Failed to get feed history")
+		return
+	}
+
+	response := []ResponseFetchAttempt{}
+	for _, attempt := range attempts {
+		response = append(response, fetchAttemptFromDBFetchAttempt(attempt))
+	}
+
+	respondWithJSON(w, 200, response)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/PFrek/gorss/internal/scraper"
+	"github.com/google/uuid"
+)
+
+type ResponsePostRevision struct {
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	PostID      uuid.UUID      `json:"post_id"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt time.Time      `json:"published_at"`
+	Content     *string        `json:"content"`
+}
+
+func postRevisionFromDBPostRevision(revision database.PostRevision) ResponsePostRevision {
+	// Revisions may have been copied from posts stored before HTML was
+	// sanitized at ingest, so they are always sanitized on the way out.
+	description, _ := scraper.SanitizedRenditions(revision.Description, revision.Url)
+	content, _ := scraper.SanitizedRenditions(revision.Content, revision.Url)
+
+	return ResponsePostRevision{
+		ID:          revision.ID,
+		CreatedAt:   revision.CreatedAt,
+		PostID:      revision.PostID,
+		Title:       revision.Title,
+		Url:         revision.Url,
+		Description: description,
+		PublishedAt: revision.PublishedAt,
+		Content:     nullStringToPtr(content),
+	}
+}
+
+func (config *ApiConfig) GetPostRevisionsHandler(w http.ResponseWriter, req *http.Request, user database.User) {
+	idStr := req.PathValue("postID")
+	postID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondWithError(w, 400, "Invalid Post ID")
+		return
+	}
+
+	ctx := req.Context()
+
+	// Only the posts of followed feeds are visible, as in GetPostsHandler.
+	_, err = config.DB.GetPostByUser(ctx, database.GetPostByUserParams{
+		ID:     postID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, 404, "Post Not Found")
+		return
+	}
+
+	revisions, err := config.DB.GetPostRevisions(ctx, postID)
+	if err != nil {
+		respondWithError(w, 500, "Failed to get post revisions")
+		return
+	}
+
+	response := []ResponsePostRevision{}
+	for _, revision := range revisions {
+		response = append(response, postRevisionFromDBPostRevision(revision))
+	}
+
+	respondWithJSON(w, 200, response)
+}
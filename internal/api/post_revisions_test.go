@@ -0,0 +1,25 @@
+package api
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/PFrek/gorss/internal/database"
+)
+
+func TestPostRevisionFromDBPostRevision(t *testing.T) {
+	revision := postRevisionFromDBPostRevision(database.PostRevision{
+		Url:     "https://example.com/1",
+		Content: sql.NullString{String: `<p>Old content</p><script>alert(1)</script>`, Valid: true},
+	})
+
+	if revision.Content == nil || !strings.Contains(*revision.Content, "Old content") || strings.Contains(*revision.Content, "script") {
+		t.Fatalf("Expected sanitized content, got %v", revision.Content)
+	}
+
+	revision = postRevisionFromDBPostRevision(database.PostRevision{Url: "https://example.com/1"})
+	if revision.Content != nil {
+		t.Fatalf("Expected no content, got %v", *revision.Content)
+	}
+}
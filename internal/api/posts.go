@@ -1,37 +1,130 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/PFrek/gorss/internal/database"
+	"github.com/PFrek/gorss/internal/scraper"
 	"github.com/google/uuid"
 )
 
+type ResponseEnclosure struct {
+	Url    string `json:"url"`
+	Type   string `json:"type"`
+	Length *int64 `json:"length"`
+}
+
 type ResponsePost struct {
-	ID          uuid.UUID      `json:"id"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	Title       string         `json:"title"`
-	Url         string         `json:"url"`
-	Description sql.NullString `json:"description"`
-	PublishedAt time.Time      `json:"published_at"`
-	FeedID      uuid.UUID      `json:"feed_id"`
+	ID              uuid.UUID           `json:"id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	Title           string              `json:"title"`
+	Url             string              `json:"url"`
+	Description     sql.NullString      `json:"description"`
+	PublishedAt     time.Time           `json:"published_at"`
+	FeedID          uuid.UUID           `json:"feed_id"`
+	Content         *string             `json:"content"`
+	DescriptionText *string             `json:"description_text"`
+	ContentText     *string             `json:"content_text"`
+	Author          *string             `json:"author"`
+	CommentsUrl     *string             `json:"comments_url"`
+	Categories      []string            `json:"categories"`
+	Enclosures      []ResponseEnclosure `json:"enclosures"`
+}
+
+func nullStringToPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	result := new(string)
+	*result = value.String
+	return result
+}
+
+func enclosureFromDBEnclosure(enclosure database.PostEnclosure) ResponseEnclosure {
+	var length *int64
+	if enclosure.Length.Valid {
+		length = new(int64)
+		*length = enclosure.Length.Int64
+	}
+
+	return ResponseEnclosure{
+		Url:    enclosure.Url,
+		Type:   enclosure.Type.String,
+		Length: length,
+	}
 }
 
 func postFromDBPost(post database.Post) ResponsePost {
+	// Posts stored before HTML was sanitized at ingest have no plain-text
+	// renditions, and are sanitized on the way out instead.
+	description, descriptionText := post.Description, post.DescriptionText
+	if description.Valid && !descriptionText.Valid {
+		description, descriptionText = scraper.SanitizedRenditions(description, post.Url)
+	}
+
+	content, contentText := post.Content, post.ContentText
+	if content.Valid && !contentText.Valid {
+		content, contentText = scraper.SanitizedRenditions(content, post.Url)
+	}
+
 	return ResponsePost{
-		ID:          post.ID,
-		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   post.UpdatedAt,
-		Title:       post.Title,
-		Url:         post.Url,
-		Description: post.Description,
-		PublishedAt: post.PublishedAt,
-		FeedID:      post.FeedID,
+		ID:              post.ID,
+		CreatedAt:       post.CreatedAt,
+		UpdatedAt:       post.UpdatedAt,
+		Title:           post.Title,
+		Url:             post.Url,
+		Description:     description,
+		PublishedAt:     post.PublishedAt,
+		FeedID:          post.FeedID,
+		Content:         nullStringToPtr(content),
+		DescriptionText: nullStringToPtr(descriptionText),
+		ContentText:     nullStringToPtr(contentText),
+		Author:          nullStringToPtr(post.Author),
+		CommentsUrl:     nullStringToPtr(post.CommentsUrl),
+		Categories:      []string{},
+		Enclosures:      []ResponseEnclosure{},
+	}
+}
+
+// postsFromDBPosts converts posts for a response, loading the categories and
+// enclosures of all of them at once.
+func (config *ApiConfig) postsFromDBPosts(ctx context.Context, posts []database.Post) ([]ResponsePost, error) {
+	response := []ResponsePost{}
+	postIDs := []uuid.UUID{}
+	indexes := map[uuid.UUID]int{}
+	for i, post := range posts {
+		response = append(response, postFromDBPost(post))
+		postIDs = append(postIDs, post.ID)
+		indexes[post.ID] = i
+	}
+
+	categories, err := config.DB.GetCategoriesForPosts(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		i := indexes[category.PostID]
+		response[i].Categories = append(response[i].Categories, category.Name)
+	}
+
+	enclosures, err := config.DB.GetEnclosuresForPosts(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, enclosure := range enclosures {
+		i := indexes[enclosure.PostID]
+		response[i].Enclosures = append(response[i].Enclosures, enclosureFromDBEnclosure(enclosure))
 	}
+
+	return response, nil
 }
 
 func (config *ApiConfig) GetPostsHandler(w http.ResponseWriter, req *http.Request, user database.User) {
@@ -56,9 +149,10 @@ func (config *ApiConfig) GetPostsHandler(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	response := []ResponsePost{}
-	for _, post := range posts {
-		response = append(response, postFromDBPost(post))
+	response, err := config.postsFromDBPosts(ctx, posts)
+	if err != nil {
+		respondWithError(w, 500, "Failed to get posts")
+		return
 	}
 
 	respondWithJSON(w, 200, response)
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+type ResponseScraperStatus struct {
+	Status              string     `json:"status"`
+	Running             bool       `json:"running"`
+	IntervalSeconds     int        `json:"interval_seconds"`
+	CycleTimeoutSeconds int        `json:"cycle_timeout_seconds"`
+	StartedAt           *time.Time `json:"started_at"`
+	Cycles              int        `json:"cycles"`
+	LastCycleStartedAt  *time.Time `json:"last_cycle_started_at"`
+	LastCycleFinishedAt *time.Time `json:"last_cycle_finished_at"`
+	LastCycleFeeds      int        `json:"last_cycle_feeds"`
+	LastError           *string    `json:"last_error"`
+}
+
+func timeToPtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// GetScraperStatusHandler reports the scraper's progress, responding with 503
+// when it isn't running or its cycles have stalled.
+func (config *ApiConfig) GetScraperStatusHandler(w http.ResponseWriter, req *http.Request) {
+	status := config.Scraper.Status()
+
+	response := ResponseScraperStatus{
+		Status:              "ok",
+		Running:             status.Running,
+		IntervalSeconds:     int(status.Interval.Seconds()),
+		CycleTimeoutSeconds: int(status.CycleTimeout.Seconds()),
+		StartedAt:           timeToPtr(status.StartedAt),
+		Cycles:              status.Cycles,
+		LastCycleStartedAt:  timeToPtr(status.LastCycleStartedAt),
+		LastCycleFinishedAt: timeToPtr(status.LastCycleFinishedAt),
+		LastCycleFeeds:      status.LastCycleFeeds,
+	}
+	if status.LastError != "" {
+		response.LastError = &status.LastError
+	}
+
+	code := 200
+	if !status.Healthy(time.Now().UTC()) {
+		response.Status = "unhealthy"
+		code = 503
+	}
+
+	respondWithJSON(w, code, response)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PFrek/gorss/internal/scraper"
+)
+
+func TestGetScraperStatusHandler(t *testing.T) {
+	stopped := &scraper.Scraper{}
+
+	started := &scraper.Scraper{}
+	done := started.Start(time.Hour, 10)
+	defer func() { done <- true }()
+
+	cases := []struct {
+		name     string
+		scraper  *scraper.Scraper
+		code     int
+		expected string
+	}{
+		{"never started", stopped, http.StatusServiceUnavailable, "unhealthy"},
+		{"started", started, http.StatusOK, "ok"},
+	}
+
+	for _, c := range cases {
+		config := ApiConfig{
+			Scraper: c.scraper,
+		}
+
+		req := httptest.NewRequest("GET", "/v1/scraper/status", nil)
+		w := httptest.NewRecorder()
+		config.GetScraperStatusHandler(w, req)
+
+		if w.Code != c.code {
+			t.Fatalf("%s: expected status %d got %d", c.name, c.code, w.Code)
+		}
+
+		response := ResponseScraperStatus{}
+		err := json.NewDecoder(w.Body).Decode(&response)
+		if err != nil {
+			t.Fatalf("%s: failed to decode response: %v", c.name, err)
+		}
+		if response.Status != c.expected {
+			t.Fatalf("%s: expected '%s' got '%s'", c.name, c.expected, response.Status)
+		}
+	}
+}
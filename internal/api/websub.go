@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PFrek/gorss/internal/scraper"
+	"github.com/google/uuid"
+)
+
+// Largest content accepted from a hub push.
+const maxWebSubPushSize = 10 << 20 // 10 MiB
+
+// GetWebSubCallbackHandler answers the hub's verification of intent for a
+// subscription request by echoing its challenge. Only requests carrying the
+// token of the callback URL we sent to the hub are answered.
+func (config *ApiConfig) GetWebSubCallbackHandler(w http.ResponseWriter, req *http.Request) {
+	idStr := req.PathValue("feedID")
+	feedID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondWithError(w, 400, "Invalid Feed ID")
+		return
+	}
+
+	ctx := req.Context()
+
+	feed, err := config.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		respondWithError(w, 404, "Feed Not Found")
+		return
+	}
+
+	query := req.URL.Query()
+	mode := query.Get("hub.mode")
+	topic := query.Get("hub.topic")
+
+	if !scraper.ValidWebSubCallbackToken(feed, query.Get("token")) {
+		respondWithError(w, 404, "Unknown subscription")
+		return
+	}
+
+	if mode == "denied" {
+		log.Printf("WebSub subscription to %s denied: %s\n", topic, query.Get("hub.reason"))
+		err = config.Scraper.ConfirmWebSubLease(ctx, feed.ID, 0)
+		if err != nil {
+			respondWithError(w, 500, "Failed to update subscription")
+			return
+		}
+		w.WriteHeader(200)
+		return
+	}
+
+	if !feed.WebsubTopic.Valid || topic != feed.WebsubTopic.String {
+		respondWithError(w, 404, "Unknown topic")
+		return
+	}
+
+	challenge := query.Get("hub.challenge")
+	if challenge == "" {
+		respondWithError(w, 400, "Missing challenge")
+		return
+	}
+
+	leaseSeconds := 0
+	switch mode {
+	case "subscribe":
+		leaseSeconds, err = strconv.Atoi(query.Get("hub.lease_seconds"))
+		if err != nil || leaseSeconds <= 0 {
+			respondWithError(w, 400, "Invalid lease")
+			return
+		}
+	case "unsubscribe":
+	default:
+		respondWithError(w, 400, "Invalid mode")
+		return
+	}
+
+	err = config.Scraper.ConfirmWebSubLease(ctx, feed.ID, leaseSeconds)
+	if err != nil {
+		respondWithError(w, 500, "Failed to update subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(200)
+	w.Write([]byte(challenge))
+}
+
+// PostWebSubCallbackHandler receives content pushed by the hub. Messages for
+// feeds without an active subscription or with an invalid signature are
+// acknowledged but ignored, as the spec requires.
+func (config *ApiConfig) PostWebSubCallbackHandler(w http.ResponseWriter, req *http.Request) {
+	idStr := req.PathValue("feedID")
+	feedID, err := uuid.Parse(idStr)
+	if err != nil {
+		respondWithError(w, 400, "Invalid Feed ID")
+		return
+	}
+
+	ctx := req.Context()
+
+	feed, err := config.DB.GetFeed(ctx, feedID)
+	if err != nil {
+		respondWithError(w, 404, "Feed Not Found")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxWebSubPushSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, 413, "Request body too large")
+			return
+		}
+		respondWithError(w, 400, "Invalid request body")
+		return
+	}
+
+	if !scraper.ValidWebSubPush(feed, req.Header.Get("X-Hub-Signature"), body, time.Now().UTC()) {
+		log.Printf("Ignoring unsubscribed or unsigned WebSub push for %s\n", feed.Url)
+		w.WriteHeader(202)
+		return
+	}
+
+	inserted, err := config.Scraper.HandleWebSubPush(feed, req.Header.Get("Content-Type"), body)
+	if err != nil {
+		log.Printf("Error processing WebSub push for %s: %v\n", feed.Url, err)
+		w.WriteHeader(202)
+		return
+	}
+
+	log.Printf("Received WebSub push for %s, %d new posts\n", feed.Url, inserted)
+	w.WriteHeader(202)
+}
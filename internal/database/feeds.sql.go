@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,7 +16,7 @@ import (
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, last_fetched_at)
 VALUES ($1, $2, $3, $4, $5, $6, NULL)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
 `
 
 type CreateFeedParams struct {
@@ -45,12 +46,25 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
 
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids FROM feeds
 WHERE id = $1
 `
 
@@ -65,12 +79,25 @@ func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -90,6 +117,19 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Dead,
+			&i.WebsubHub,
+			&i.WebsubTopic,
+			&i.WebsubSecret,
+			&i.WebsubLeaseExpiresAt,
+			&i.ClaimedUntil,
+			&i.LegacyGuids,
 		); err != nil {
 			return nil, err
 		}
@@ -104,14 +144,29 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT $1
+const claimNextFeedsToFetch = `-- name: ClaimNextFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = $1
+WHERE id IN (
+  SELECT id FROM feeds
+  WHERE NOT dead
+  AND (next_fetch_at IS NULL OR next_fetch_at <= TIMEZONE('utc', NOW()))
+  AND (websub_lease_expires_at IS NULL OR websub_lease_expires_at <= TIMEZONE('utc', NOW()) + INTERVAL '1 day')
+  AND (claimed_until IS NULL OR claimed_until <= TIMEZONE('utc', NOW()))
+  ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
 `
 
-func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+type ClaimNextFeedsToFetchParams struct {
+	ClaimedUntil sql.NullTime
+	MaxFeeds     int32
+}
+
+func (q *Queries) ClaimNextFeedsToFetch(ctx context.Context, arg ClaimNextFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimNextFeedsToFetch, arg.ClaimedUntil, arg.MaxFeeds)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +182,19 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.NextFetchAt,
+			&i.FailureCount,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.Dead,
+			&i.WebsubHub,
+			&i.WebsubTopic,
+			&i.WebsubSecret,
+			&i.WebsubLeaseExpiresAt,
+			&i.ClaimedUntil,
+			&i.LegacyGuids,
 		); err != nil {
 			return nil, err
 		}
@@ -141,12 +209,40 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 	return items, nil
 }
 
+const extendFeedClaim = `-- name: ExtendFeedClaim :exec
+UPDATE feeds
+SET claimed_until = $2
+WHERE id = $1
+`
+
+type ExtendFeedClaimParams struct {
+	ID           uuid.UUID
+	ClaimedUntil sql.NullTime
+}
+
+func (q *Queries) ExtendFeedClaim(ctx context.Context, arg ExtendFeedClaimParams) error {
+	_, err := q.db.ExecContext(ctx, extendFeedClaim, arg.ID, arg.ClaimedUntil)
+	return err
+}
+
+const releaseFeedClaim = `-- name: ReleaseFeedClaim :exec
+UPDATE feeds
+SET claimed_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleaseFeedClaim(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedClaim, id)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = TIMEZONE('utc', NOW()),
+failure_count = 0,
 updated_at = TIMEZONE('utc', NOW())
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
 `
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -160,6 +256,284 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, erro
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
 	)
 	return i, err
 }
+
+const updateFeedValidators = `-- name: UpdateFeedValidators :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1
+`
+
+type UpdateFeedValidatorsParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedValidators(ctx context.Context, arg UpdateFeedValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const clearFeedLegacyGuids = `-- name: ClearFeedLegacyGuids :exec
+UPDATE feeds
+SET legacy_guids = FALSE
+WHERE id = $1
+`
+
+func (q *Queries) ClearFeedLegacyGuids(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearFeedLegacyGuids, id)
+	return err
+}
+
+const setFeedNextFetch = `-- name: SetFeedNextFetch :exec
+UPDATE feeds
+SET next_fetch_at = $2
+WHERE id = $1
+`
+
+type SetFeedNextFetchParams struct {
+	ID          uuid.UUID
+	NextFetchAt sql.NullTime
+}
+
+func (q *Queries) SetFeedNextFetch(ctx context.Context, arg SetFeedNextFetchParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedNextFetch, arg.ID, arg.NextFetchAt)
+	return err
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET failure_count = failure_count + 1,
+last_error = $1,
+last_error_at = TIMEZONE('utc', NOW()),
+next_fetch_at = $2,
+dead = failure_count + 1 >= $3::integer,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $4
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
+`
+
+type RecordFeedFailureParams struct {
+	LastError   sql.NullString
+	NextFetchAt sql.NullTime
+	MaxFailures int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure,
+		arg.LastError,
+		arg.NextFetchAt,
+		arg.MaxFailures,
+		arg.ID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
+	)
+	return i, err
+}
+
+const recordFeedSkipped = `-- name: RecordFeedSkipped :one
+UPDATE feeds
+SET last_error = $1,
+last_error_at = TIMEZONE('utc', NOW()),
+next_fetch_at = $2,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $3
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
+`
+
+type RecordFeedSkippedParams struct {
+	LastError   sql.NullString
+	NextFetchAt sql.NullTime
+	ID          uuid.UUID
+}
+
+func (q *Queries) RecordFeedSkipped(ctx context.Context, arg RecordFeedSkippedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedSkipped, arg.LastError, arg.NextFetchAt, arg.ID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
+	)
+	return i, err
+}
+
+const getFeedByUrl = `-- name: GetFeedByUrl :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids FROM feeds
+WHERE url = $1
+`
+
+func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
+	)
+	return i, err
+}
+
+const updateFeedUrl = `-- name: UpdateFeedUrl :one
+UPDATE feeds
+SET url = $2,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, failure_count, last_error, last_error_at, dead, websub_hub, websub_topic, websub_secret, websub_lease_expires_at, claimed_until, legacy_guids
+`
+
+type UpdateFeedUrlParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedUrl(ctx context.Context, arg UpdateFeedUrlParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedUrl, arg.ID, arg.Url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.FailureCount,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Dead,
+		&i.WebsubHub,
+		&i.WebsubTopic,
+		&i.WebsubSecret,
+		&i.WebsubLeaseExpiresAt,
+		&i.ClaimedUntil,
+		&i.LegacyGuids,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const setFeedWebSubSubscription = `-- name: SetFeedWebSubSubscription :exec
+UPDATE feeds
+SET websub_hub = $2,
+websub_topic = $3,
+websub_secret = $4
+WHERE id = $1
+`
+
+type SetFeedWebSubSubscriptionParams struct {
+	ID           uuid.UUID
+	WebsubHub    sql.NullString
+	WebsubTopic  sql.NullString
+	WebsubSecret sql.NullString
+}
+
+func (q *Queries) SetFeedWebSubSubscription(ctx context.Context, arg SetFeedWebSubSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedWebSubSubscription,
+		arg.ID,
+		arg.WebsubHub,
+		arg.WebsubTopic,
+		arg.WebsubSecret,
+	)
+	return err
+}
+
+const setFeedWebSubLease = `-- name: SetFeedWebSubLease :exec
+UPDATE feeds
+SET websub_lease_expires_at = $2
+WHERE id = $1
+`
+
+type SetFeedWebSubLeaseParams struct {
+	ID                   uuid.UUID
+	WebsubLeaseExpiresAt sql.NullTime
+}
+
+func (q *Queries) SetFeedWebSubLease(ctx context.Context, arg SetFeedWebSubLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedWebSubLease, arg.ID, arg.WebsubLeaseExpiresAt)
+	return err
+}
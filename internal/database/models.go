@@ -12,13 +12,26 @@ import (
 )
 
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	Etag                 sql.NullString
+	LastModified         sql.NullString
+	NextFetchAt          sql.NullTime
+	FailureCount         int32
+	LastError            sql.NullString
+	LastErrorAt          sql.NullTime
+	Dead                 bool
+	WebsubHub            sql.NullString
+	WebsubTopic          sql.NullString
+	WebsubSecret         sql.NullString
+	WebsubLeaseExpiresAt sql.NullTime
+	ClaimedUntil         sql.NullTime
+	LegacyGuids          bool
 }
 
 type FeedFollow struct {
@@ -29,15 +42,79 @@ type FeedFollow struct {
 	UserID    uuid.UUID
 }
 
+type FetchAttempt struct {
+	ID            uuid.UUID
+	FeedID        uuid.UUID
+	AttemptedAt   time.Time
+	StatusCode    sql.NullInt32
+	DurationMs    int32
+	Bytes         int32
+	ItemsFound    int32
+	ItemsInserted int32
+	Error         sql.NullString
+	Recoveries    []string
+}
+
+type HostDeferral struct {
+	Host          string
+	DeferredUntil time.Time
+}
+
+type PlaybackPosition struct {
+	UserID          uuid.UUID
+	EnclosureID     uuid.UUID
+	UpdatedAt       time.Time
+	PositionSeconds int32
+	Completed       bool
+}
+
 type Post struct {
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Title              string
+	Url                string
+	Description        sql.NullString
+	PublishedAt        time.Time
+	FeedID             uuid.UUID
+	Guid               string
+	ContentHash        string
+	Content            sql.NullString
+	Author             sql.NullString
+	CommentsUrl        sql.NullString
+	DurationSeconds    sql.NullInt32
+	Episode            sql.NullInt32
+	Season             sql.NullInt32
+	ImageUrl           sql.NullString
+	Explicit           sql.NullBool
+	DescriptionText    sql.NullString
+	ContentText        sql.NullString
+	ContentHashVersion int32
+}
+
+type PostCategory struct {
+	PostID uuid.UUID
+	Name   string
+}
+
+type PostEnclosure struct {
+	ID     uuid.UUID
+	PostID uuid.UUID
+	Url    string
+	Type   sql.NullString
+	Length sql.NullInt64
+}
+
+type PostRevision struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	PostID      uuid.UUID
 	Title       string
 	Url         string
 	Description sql.NullString
 	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ContentHash string
+	Content     sql.NullString
 }
 
 type User struct {
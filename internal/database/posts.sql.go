@@ -14,20 +14,33 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
-	FeedID      uuid.UUID
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Title              string
+	Url                string
+	Description        sql.NullString
+	PublishedAt        time.Time
+	FeedID             uuid.UUID
+	Guid               string
+	ContentHash        string
+	Content            sql.NullString
+	Author             sql.NullString
+	CommentsUrl        sql.NullString
+	DurationSeconds    sql.NullInt32
+	Episode            sql.NullInt32
+	Season             sql.NullInt32
+	ImageUrl           sql.NullString
+	Explicit           sql.NullBool
+	DescriptionText    sql.NullString
+	ContentText        sql.NullString
+	ContentHashVersion int32
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -40,6 +53,19 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.ContentHash,
+		arg.Content,
+		arg.Author,
+		arg.CommentsUrl,
+		arg.DurationSeconds,
+		arg.Episode,
+		arg.Season,
+		arg.ImageUrl,
+		arg.Explicit,
+		arg.DescriptionText,
+		arg.ContentText,
+		arg.ContentHashVersion,
 	)
 	var i Post
 	err := row.Scan(
@@ -51,12 +77,25 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.Explicit,
+		&i.DescriptionText,
+		&i.ContentText,
+		&i.ContentHashVersion,
 	)
 	return i, err
 }
 
 const getPostsByUser = `-- name: GetPostsByUser :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version FROM posts
 WHERE feed_id IN (SELECT feed_id FROM feed_follows
 	WHERE user_id = $1
 )
@@ -87,6 +126,19 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.ContentHash,
+			&i.Content,
+			&i.Author,
+			&i.CommentsUrl,
+			&i.DurationSeconds,
+			&i.Episode,
+			&i.Season,
+			&i.ImageUrl,
+			&i.Explicit,
+			&i.DescriptionText,
+			&i.ContentText,
+			&i.ContentHashVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -100,3 +152,221 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 	}
 	return items, nil
 }
+
+const movePosts = `-- name: MovePosts :exec
+UPDATE posts
+SET feed_id = $1,
+updated_at = TIMEZONE('utc', NOW())
+WHERE feed_id = $2
+AND guid NOT IN (SELECT guid FROM posts WHERE feed_id = $1)
+`
+
+type MovePostsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) error {
+	_, err := q.db.ExecContext(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const countFeedPosts = `-- name: CountFeedPosts :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountFeedPosts(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedPosts, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getPostByUser = `-- name: GetPostByUser :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version FROM posts
+WHERE id = $1
+AND feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = $2)
+`
+
+type GetPostByUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetPostByUser(ctx context.Context, arg GetPostByUserParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByUser, arg.ID, arg.UserID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.Explicit,
+		&i.DescriptionText,
+		&i.ContentText,
+		&i.ContentHashVersion,
+	)
+	return i, err
+}
+
+const getPostByGuid = `-- name: GetPostByGuid :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version FROM posts
+WHERE feed_id = $1 AND guid = $2
+`
+
+type GetPostByGuidParams struct {
+	FeedID uuid.UUID
+	Guid   string
+}
+
+func (q *Queries) GetPostByGuid(ctx context.Context, arg GetPostByGuidParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByGuid, arg.FeedID, arg.Guid)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.Explicit,
+		&i.DescriptionText,
+		&i.ContentText,
+		&i.ContentHashVersion,
+	)
+	return i, err
+}
+
+const adoptLegacyPostGuid = `-- name: AdoptLegacyPostGuid :exec
+UPDATE posts
+SET guid = $1
+WHERE feed_id = $2
+AND guid = $3
+AND NOT EXISTS (SELECT 1 FROM posts WHERE feed_id = $2 AND guid = $1)
+`
+
+type AdoptLegacyPostGuidParams struct {
+	Guid       string
+	FeedID     uuid.UUID
+	LegacyGuid string
+}
+
+func (q *Queries) AdoptLegacyPostGuid(ctx context.Context, arg AdoptLegacyPostGuidParams) error {
+	_, err := q.db.ExecContext(ctx, adoptLegacyPostGuid, arg.Guid, arg.FeedID, arg.LegacyGuid)
+	return err
+}
+
+const updatePostContent = `-- name: UpdatePostContent :one
+UPDATE posts
+SET title = $2,
+url = $3,
+description = $4,
+published_at = $5,
+content_hash = $6,
+content = $7,
+author = $8,
+comments_url = $9,
+duration_seconds = $10,
+episode = $11,
+season = $12,
+image_url = $13,
+explicit = $14,
+description_text = $15,
+content_text = $16,
+content_hash_version = $17,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $1
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version
+`
+
+type UpdatePostContentParams struct {
+	ID                 uuid.UUID
+	Title              string
+	Url                string
+	Description        sql.NullString
+	PublishedAt        time.Time
+	ContentHash        string
+	Content            sql.NullString
+	Author             sql.NullString
+	CommentsUrl        sql.NullString
+	DurationSeconds    sql.NullInt32
+	Episode            sql.NullInt32
+	Season             sql.NullInt32
+	ImageUrl           sql.NullString
+	Explicit           sql.NullBool
+	DescriptionText    sql.NullString
+	ContentText        sql.NullString
+	ContentHashVersion int32
+}
+
+func (q *Queries) UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePostContent,
+		arg.ID,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.ContentHash,
+		arg.Content,
+		arg.Author,
+		arg.CommentsUrl,
+		arg.DurationSeconds,
+		arg.Episode,
+		arg.Season,
+		arg.ImageUrl,
+		arg.Explicit,
+		arg.DescriptionText,
+		arg.ContentText,
+		arg.ContentHashVersion,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.ContentHash,
+		&i.Content,
+		&i.Author,
+		&i.CommentsUrl,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.Season,
+		&i.ImageUrl,
+		&i.Explicit,
+		&i.DescriptionText,
+		&i.ContentText,
+		&i.ContentHashVersion,
+	)
+	return i, err
+}
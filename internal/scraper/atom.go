@@ -0,0 +1,147 @@
+package scraper
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// AtomText holds an Atom text construct. XHTML content is kept as markup,
+// while text and html content are read as (unescaped) character data.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t *AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return t.Text
+}
+
+type AtomEntry struct {
+	PodcastExtensions
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Links   []AtomLink `xml:"link"`
+	Summary *AtomText  `xml:"summary"`
+	// Namespaced so it does not clash with media:content.
+	Content    *AtomText      `xml:"http://www.w3.org/2005/Atom content"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Authors    []string       `xml:"author>name"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+func findLink(links []AtomLink, rel string) string {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+
+	return ""
+}
+
+func (entry AtomEntry) link() string {
+	for _, link := range entry.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+
+	if len(entry.Links) > 0 {
+		return entry.Links[0].Href
+	}
+
+	return ""
+}
+
+func (entry AtomEntry) toFeedItem() FeedItem {
+	var description *string
+	if entry.Summary != nil {
+		description = new(string)
+		*description = entry.Summary.String()
+	} else if entry.Content != nil {
+		description = new(string)
+		*description = entry.Content.String()
+	}
+
+	var content *string
+	if entry.Content != nil {
+		content = new(string)
+		*content = entry.Content.String()
+	}
+
+	pubDate := entry.Published
+	if pubDate == "" {
+		pubDate = entry.Updated
+	}
+
+	categories := []string{}
+	for _, category := range entry.Categories {
+		categories = append(categories, category.Term)
+	}
+
+	enclosures := []Enclosure{}
+	for _, link := range entry.Links {
+		if link.Rel == "enclosure" {
+			enclosures = append(enclosures, Enclosure{
+				Url:    link.Href,
+				Type:   link.Type,
+				Length: link.Length,
+			})
+		}
+	}
+
+	feedItem := FeedItem{
+		GUID:        entry.ID,
+		Title:       entry.Title,
+		Link:        entry.link(),
+		Description: description,
+		PubDate:     pubDate,
+		Content:     content,
+		Author:      strings.Join(entry.Authors, ", "),
+		Categories:  categories,
+		CommentsURL: findLink(entry.Links, "replies"),
+		Enclosures:  enclosures,
+	}
+	entry.PodcastExtensions.apply(&feedItem)
+
+	return feedItem
+}
+
+func (feed AtomFeed) toFeedData() FeedData {
+	feedData := FeedData{
+		Title:   feed.Title,
+		HubURL:  findLink(feed.Links, "hub"),
+		SelfURL: findLink(feed.Links, "self"),
+	}
+	for _, entry := range feed.Entries {
+		feedData.Items = append(feedData.Items, entry.toFeedItem())
+	}
+
+	return feedData
+}
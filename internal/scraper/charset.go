@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+	// Matches the encoding attribute of an XML declaration.
+	xmlDeclEncoding = regexp.MustCompile(`^(\s*<\?xml[^>]*?\sencoding\s*=\s*)(?:"[^"]*"|'[^']*')`)
+)
+
+// documentCharset returns the character set a document is encoded in, in
+// order of precedence: byte order mark, Content-Type charset parameter and
+// XML declaration. It returns "" when none of them name one.
+func documentCharset(contentType string, data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return "utf-8"
+	}
+	if bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		return "utf-16be"
+	}
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) {
+		return "utf-16le"
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err == nil && params["charset"] != "" {
+		return strings.ToLower(strings.TrimSpace(params["charset"]))
+	}
+
+	match := xmlDeclEncoding.FindSubmatch(data)
+	if match != nil {
+		label := data[len(match[1]):len(match[0])]
+		return strings.ToLower(strings.TrimSpace(string(label[1 : len(label)-1])))
+	}
+
+	return ""
+}
+
+// toUTF8 converts a fetched document to UTF-8 so parsers only ever see one
+// encoding. The XML declaration, if any, is rewritten to match. Documents in
+// an unknown or undeclared charset that are not valid UTF-8 are read as
+// Windows-1252, and invalid bytes left over are replaced rather than failing
+// the whole feed.
+func toUTF8(contentType string, data []byte) []byte {
+	label := documentCharset(contentType, data)
+
+	encoding, name := charset.Lookup(label)
+	if encoding == nil && !utf8.Valid(data) {
+		encoding, name = charset.Lookup("windows-1252")
+	}
+
+	if encoding != nil && name != "utf-8" {
+		converted, err := encoding.NewDecoder().Bytes(data)
+		if err == nil {
+			data = converted
+		}
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	data = bytes.ToValidUTF8(data, []byte("\uFFFD"))
+
+	return xmlDeclEncoding.ReplaceAll(data, []byte(`${1}"UTF-8"`))
+}
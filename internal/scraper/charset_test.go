@@ -0,0 +1,92 @@
+package scraper
+
+import "testing"
+
+func rssWithTitle(declaration string, title []byte) []byte {
+	data := []byte(declaration + `<rss version="2.0"><channel><title>`)
+	data = append(data, title...)
+	return append(data, []byte(`</title></channel></rss>`)...)
+}
+
+func TestParserRegistryCharsets(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		data        []byte
+		expected    string
+	}{
+		{
+			name:        "xml declaration latin-1",
+			contentType: "application/rss+xml",
+			data:        rssWithTitle(`<?xml version="1.0" encoding="ISO-8859-1"?>`, []byte("Caf\xe9")),
+			expected:    "Café",
+		},
+		{
+			name:        "windows-1252 quotes",
+			contentType: "application/rss+xml",
+			data:        rssWithTitle(`<?xml version="1.0" encoding="windows-1252"?>`, []byte("\x93Hi\x94")),
+			expected:    "“Hi”",
+		},
+		{
+			name:        "shift_jis",
+			contentType: "text/xml",
+			data:        rssWithTitle(`<?xml version="1.0" encoding="Shift_JIS"?>`, []byte("\x93\xfa\x96\x7b")),
+			expected:    "日本",
+		},
+		{
+			name:        "content-type koi8-r",
+			contentType: "application/xml; charset=KOI8-R",
+			data:        rssWithTitle(`<?xml version="1.0"?>`, []byte("\xf0\xd2\xc9\xd7\xc5\xd4")),
+			expected:    "Привет",
+		},
+		{
+			name:        "content-type overrides declaration",
+			contentType: "application/xml; charset=ISO-8859-1",
+			data:        rssWithTitle(`<?xml version="1.0" encoding="UTF-8"?>`, []byte("Caf\xe9")),
+			expected:    "Café",
+		},
+		{
+			name:        "utf-8 with bom",
+			contentType: "application/rss+xml",
+			data:        append([]byte("\xef\xbb\xbf"), rssWithTitle(`<?xml version="1.0"?>`, []byte("Café"))...),
+			expected:    "Café",
+		},
+		{
+			name:        "undeclared invalid utf-8",
+			contentType: "application/rss+xml",
+			data:        rssWithTitle("", []byte("Caf\xe9")),
+			expected:    "Café",
+		},
+		{
+			name:        "declared utf-8 with invalid bytes",
+			contentType: "application/rss+xml",
+			data:        rssWithTitle(`<?xml version="1.0" encoding="UTF-8"?>`, []byte("Caf\xc3\xa9 \xff")),
+			expected:    "Café �",
+		},
+	}
+
+	registry := DefaultParserRegistry()
+	for _, c := range cases {
+		feedData, err := registry.Parse(c.contentType, c.data)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if feedData.Title != c.expected {
+			t.Fatalf("%s: expected title '%s' got '%s'", c.name, c.expected, feedData.Title)
+		}
+	}
+}
+
+func TestParseXMLCharsetReader(t *testing.T) {
+	data := rssWithTitle(`<?xml version="1.0" encoding="ISO-8859-1"?>`, []byte("Caf\xe9"))
+
+	feedData, err := RSSParser{}.Parse(data)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if feedData.Title != "Café" {
+		t.Fatalf("Invalid title: expected 'Café' got '%s'", feedData.Title)
+	}
+}
@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+)
+
+const defaultClaimTimeout = 10 * time.Minute
+
+// claimFeeds reserves up to numFeeds feeds due for fetching for this
+// instance. Rows are locked with SKIP LOCKED while being claimed, so
+// instances scraping the same database at once never claim the same feed.
+// As requests to a host are spaced out, the claims also cover the batch
+// waiting on a single host.
+func (s *Scraper) claimFeeds(numFeeds int) ([]database.Feed, error) {
+	return s.DB.ClaimNextFeedsToFetch(context.Background(), database.ClaimNextFeedsToFetchParams{
+		ClaimedUntil: sql.NullTime{
+			Time:  time.Now().UTC().Add(s.claimDuration(numFeeds)),
+			Valid: true,
+		},
+		MaxFeeds: int32(numFeeds),
+	})
+}
+
+// claimDuration is how long a batch of numFeeds feeds is claimed for, which
+// is also how long a scraping cycle may take.
+func (s *Scraper) claimDuration(numFeeds int) time.Duration {
+	return s.claimTimeout() + time.Duration(numFeeds)*s.hostDelay()
+}
+
+// extendClaim keeps the feed claimed for the claim timeout from start, when
+// its fetch begins, however long it waited for its host.
+func (s *Scraper) extendClaim(feed database.Feed, start time.Time) {
+	err := s.DB.ExtendFeedClaim(context.Background(), database.ExtendFeedClaimParams{
+		ID: feed.ID,
+		ClaimedUntil: sql.NullTime{
+			Time:  start.Add(s.claimTimeout()),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		log.Printf("Error extending claim on feed %s: %v\n", feed.Url, err)
+	}
+}
+
+// releaseClaim lets other instances claim the feed again once it's due. By
+// then its next fetch has been scheduled, so it isn't picked up right away.
+func (s *Scraper) releaseClaim(feed database.Feed) {
+	err := s.DB.ReleaseFeedClaim(context.Background(), feed.ID)
+	if err != nil {
+		log.Printf("Error releasing claim on feed %s: %v\n", feed.Url, err)
+	}
+}
+
+func (s *Scraper) claimTimeout() time.Duration {
+	if s.ClaimTimeout <= 0 {
+		return defaultClaimTimeout
+	}
+
+	return s.ClaimTimeout
+}
@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// pubDateLayouts are tried in order against a date after normalizeDate has
+// dropped the weekday, translated the month and turned the zone into a
+// numeric offset.
+var pubDateLayouts = []string{
+	// RFC 822 / RFC 1123, with four or two digit years
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	// Month first, e.g. "June 5, 2024 10:00 AM"
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 3:04 PM",
+	"Jan 2 2006",
+	// RFC 3339 / ISO 8601
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// dateMonths maps English and non-English month names and abbreviations to
+// the English abbreviation expected by the layouts.
+var dateMonths = map[string]string{}
+
+func init() {
+	months := [][]string{
+		{"jan", "january", "janvier", "janv", "januar", "enero", "ene", "gennaio", "gen", "janeiro", "januari"},
+		{"feb", "february", "février", "févr", "fév", "februar", "febrero", "febbraio", "fevereiro", "fev", "februari"},
+		{"mar", "march", "mars", "märz", "mär", "mrz", "marzo", "março", "maart", "mrt"},
+		{"apr", "april", "avril", "avr", "abril", "abr", "aprile"},
+		{"may", "mai", "mayo", "maggio", "mag", "maio", "mei"},
+		{"jun", "june", "juin", "juni", "junio", "giugno", "giu", "junho"},
+		{"jul", "july", "juillet", "juil", "juli", "julio", "luglio", "lug", "julho"},
+		{"aug", "august", "août", "aoû", "agosto", "ago", "augustus"},
+		{"sep", "sept", "september", "septembre", "septiembre", "setiembre", "settembre", "setembro", "set"},
+		{"oct", "october", "octobre", "oktober", "okt", "octubre", "ottobre", "ott", "outubro", "out"},
+		{"nov", "november", "novembre", "noviembre", "novembro"},
+		{"dec", "december", "décembre", "déc", "dezember", "dez", "diciembre", "dic", "dicembre", "dezembro"},
+	}
+
+	for i, names := range months {
+		abbreviation := time.Month(i + 1).String()[:3]
+		for _, name := range names {
+			dateMonths[name] = abbreviation
+		}
+	}
+}
+
+// dateZones maps the timezone abbreviations seen in feeds to their offsets.
+// Go would otherwise parse unknown abbreviations as UTC.
+var dateZones = map[string]string{
+	"Z":    "+0000",
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"MET":  "+0100",
+	"MEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+var colonOffset = regexp.MustCompile(`^([+-]\d\d):(\d\d)$`)
+
+// normalizeDate rewrites a date into a form pubDateLayouts can parse: the
+// weekday is dropped (it adds nothing and is often wrong or localized),
+// month names become English abbreviations, zone abbreviations and "+hh:mm"
+// offsets become "+hhmm", and trailing comments like "(PDT)" are removed.
+func normalizeDate(date string) string {
+	date = strings.ReplaceAll(date, ",", " ")
+	fields := strings.Fields(date)
+
+	if len(fields) > 1 && strings.HasPrefix(fields[len(fields)-1], "(") {
+		fields = fields[:len(fields)-1]
+	}
+
+	// Some weekday abbreviations are also month names, e.g. French "mar."
+	// for mardi, so a leading month is only kept when no other follows.
+	if len(fields) > 1 && isWord(fields[0]) && (dateMonth(fields[0]) == "" || hasDateMonth(fields[1:])) {
+		fields = fields[1:]
+	}
+
+	for i, field := range fields {
+		if month := dateMonth(field); month != "" {
+			fields[i] = month
+		}
+	}
+
+	if len(fields) > 1 {
+		last := fields[len(fields)-1]
+		if offset, ok := dateZones[strings.ToUpper(last)]; ok {
+			fields[len(fields)-1] = offset
+		} else if colonOffset.MatchString(last) {
+			fields[len(fields)-1] = colonOffset.ReplaceAllString(last, "$1$2")
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func dateMonth(field string) string {
+	return dateMonths[strings.ToLower(strings.TrimSuffix(field, "."))]
+}
+
+func hasDateMonth(fields []string) bool {
+	for _, field := range fields {
+		if dateMonth(field) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWord(field string) bool {
+	field = strings.TrimSuffix(field, ".")
+	if field == "" {
+		return false
+	}
+
+	for _, r := range field {
+		if r >= '0' && r <= '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parsePubDate parses the publication date of an item, returning it in UTC.
+// Dates without a zone are taken to be UTC.
+func parsePubDate(pubDate string) (time.Time, error) {
+	normalized := normalizeDate(pubDate)
+
+	for _, layout := range pubDateLayouts {
+		result, err := time.Parse(layout, normalized)
+		if err == nil {
+			return result.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Failed to convert date from string: %s", pubDate)
+}
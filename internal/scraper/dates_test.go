@@ -0,0 +1,84 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePubDate(t *testing.T) {
+	expected := time.Date(2024, time.June, 5, 14, 30, 0, 0, time.UTC)
+	midnight := time.Date(2024, time.June, 5, 0, 0, 0, 0, time.UTC)
+
+	// Formats collected from feeds in the wild.
+	cases := []struct {
+		pubDate  string
+		expected time.Time
+	}{
+		// RFC 1123 / RFC 822
+		{"Wed, 05 Jun 2024 14:30:00 GMT", expected},
+		{"Wed, 05 Jun 2024 14:30:00 +0000", expected},
+		{"Wed, 5 Jun 2024 16:30:00 +0200", expected},
+		{"Wed, 05 Jun 2024 14:30 +0000", expected},
+		{"Wed, 05 Jun 24 14:30:00 +0000", expected},
+		{"05 Jun 24 14:30 GMT", expected},
+		{"Wed, 05 Jun 2024 14:30:00 Z", expected},
+		{"Wed, 05 Jun 2024 14:30:00 UT", expected},
+		{"  Wed,  05 Jun 2024 14:30:00 GMT  ", expected},
+		{"Wed, 05 Jun 2024 14:30:00 +0000 (UTC)", expected},
+		// Missing or wrong weekday
+		{"05 Jun 2024 14:30:00 +0000", expected},
+		{"Mon, 05 Jun 2024 14:30:00 +0000", expected},
+		{"Wednesday, 05 June 2024 14:30:00 +0000", expected},
+		{"Wed 05 Jun 2024 14:30:00 +0000", expected},
+		// Named timezones
+		{"Wed, 05 Jun 2024 10:30:00 EDT", expected},
+		{"Wed, 05 Jun 2024 09:30:00 EST", expected},
+		{"Wed, 05 Jun 2024 07:30:00 PDT", expected},
+		{"Wed, 05 Jun 2024 06:30:00 pst", expected},
+		{"Wed, 05 Jun 2024 16:30:00 CEST", expected},
+		{"Wed, 05 Jun 2024 16:30:00 +02:00", expected},
+		// Non-English month and weekday names
+		{"mer., 05 juin 2024 16:30:00 +0200", expected},
+		{"mar., 05 juin 2024 16:30:00 +0200", expected},
+		{"Mi, 05 Juni 2024 16:30:00 +0200", expected},
+		{"mié, 05 jun 2024 14:30:00 GMT", expected},
+		{"05 giugno 2024 14:30:00 +0000", expected},
+		{"5 März 2024", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"05 déc. 2024", time.Date(2024, time.December, 5, 0, 0, 0, 0, time.UTC)},
+		// Month first
+		{"June 5, 2024 14:30:00 +0000", expected},
+		{"June 5, 2024 2:30 PM", expected},
+		{"Jun 5, 2024", midnight},
+		// RFC 3339 / ISO 8601
+		{"2024-06-05T14:30:00Z", expected},
+		{"2024-06-05T16:30:00+02:00", expected},
+		{"2024-06-05T14:30:00.000Z", expected},
+		{"2024-06-05T16:30:00+0200", expected},
+		{"2024-06-05T14:30Z", expected},
+		{"2024-06-05T14:30:00", expected},
+		{"2024-06-05 14:30:00", expected},
+		{"2024-06-05 10:30:00 -0400", expected},
+		{"2024-06-05 10:30:00 EDT", expected},
+		{"2024-06-05", midnight},
+	}
+
+	for _, c := range cases {
+		result, err := parsePubDate(c.pubDate)
+		if err != nil {
+			t.Fatalf("%q: %v", c.pubDate, err)
+		}
+
+		if !result.Equal(c.expected) || result.Location() != time.UTC {
+			t.Fatalf("%q: expected %v got %v", c.pubDate, c.expected, result)
+		}
+	}
+}
+
+func TestParsePubDateInvalid(t *testing.T) {
+	for _, pubDate := range []string{"", "yesterday", "Wed, 45 Jun 2024 14:30:00 GMT", "2024-13-05"} {
+		_, err := parsePubDate(pubDate)
+		if err == nil {
+			t.Fatalf("%q: expected an error", pubDate)
+		}
+	}
+}
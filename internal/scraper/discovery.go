@@ -0,0 +1,206 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Link types advertised by <link rel="alternate"> tags that point to feeds.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+// Paths probed when a page doesn't advertise any feed.
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.xml",
+	"/index.xml",
+	"/feed.json",
+}
+
+type DiscoveredFeed struct {
+	Url   string `json:"url"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	// Set when discovery already fetched the feed itself, so it doesn't need
+	// to be fetched again to validate it.
+	Feed *FetchedFeed `json:"-"`
+}
+
+// FetchedFeed is a feed fetched outside of the scraping cycle, along with
+// the validators it was served with.
+type FetchedFeed struct {
+	Data         FeedData
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+// Discover finds the feeds offered at pageUrl. A URL that already serves a
+// feed is returned as the only candidate; for HTML pages the advertised
+// alternate links are returned, falling back to probing common feed paths.
+func (s *Scraper) Discover(ctx context.Context, pageUrl string) ([]DiscoveredFeed, error) {
+	body, header, finalUrl, err := s.get(ctx, pageUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	feedData, err := s.parsers().Parse(header.Get("Content-Type"), body)
+	if err == nil {
+		return []DiscoveredFeed{
+			{
+				Url:   pageUrl,
+				Title: feedData.Title,
+				Feed:  fetchedFeed(feedData, header),
+			},
+		}, nil
+	}
+
+	candidates := findFeedLinks(body, finalUrl)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidateUrl, err := finalUrl.Parse(path)
+		if err != nil {
+			continue
+		}
+
+		body, header, _, err := s.get(ctx, candidateUrl.String())
+		if err != nil {
+			continue
+		}
+
+		feedData, err := s.parsers().Parse(header.Get("Content-Type"), body)
+		if err != nil {
+			continue
+		}
+
+		return []DiscoveredFeed{
+			{
+				Url:   candidateUrl.String(),
+				Title: feedData.Title,
+				Feed:  fetchedFeed(feedData, header),
+			},
+		}, nil
+	}
+
+	return []DiscoveredFeed{}, nil
+}
+
+// FetchFeedData fetches and parses a feed outside of the scraping cycle, e.g.
+// to validate it before it is registered.
+func (s *Scraper) FetchFeedData(ctx context.Context, feedUrl string) (FetchedFeed, error) {
+	body, header, _, err := s.get(ctx, feedUrl)
+	if err != nil {
+		return FetchedFeed{}, err
+	}
+
+	feedData, err := s.parsers().Parse(header.Get("Content-Type"), body)
+	if err != nil {
+		return FetchedFeed{}, fmt.Errorf("failed to parse feed: %v", err)
+	}
+
+	return *fetchedFeed(feedData, header), nil
+}
+
+func fetchedFeed(feedData FeedData, header http.Header) *FetchedFeed {
+	return &FetchedFeed{
+		Data:         feedData,
+		Etag:         headerToNullString(header, "ETag"),
+		LastModified: headerToNullString(header, "Last-Modified"),
+	}
+}
+
+func (s *Scraper) get(ctx context.Context, rawUrl string) ([]byte, http.Header, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawUrl, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create GET request: %v", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, nil, nil, fmt.Errorf("failed HTTP request: status %v", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	return body, resp.Header, resp.Request.URL, nil
+}
+
+// findFeedLinks extracts the feeds advertised by an HTML page's
+// <link rel="alternate"> tags, resolving their hrefs against the page URL.
+func findFeedLinks(page []byte, pageUrl *url.URL) []DiscoveredFeed {
+	candidates := []DiscoveredFeed{}
+	seen := map[string]bool{}
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return candidates
+		}
+
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		if token.Data != "link" {
+			continue
+		}
+
+		attrs := map[string]string{}
+		for _, attr := range token.Attr {
+			attrs[attr.Key] = strings.TrimSpace(attr.Val)
+		}
+
+		linkType := strings.ToLower(attrs["type"])
+		if !hasRel(attrs["rel"], "alternate") || !feedLinkTypes[linkType] || attrs["href"] == "" {
+			continue
+		}
+
+		href, err := pageUrl.Parse(attrs["href"])
+		if err != nil || seen[href.String()] {
+			continue
+		}
+		seen[href.String()] = true
+
+		candidates = append(candidates, DiscoveredFeed{
+			Url:   href.String(),
+			Title: attrs["title"],
+			Type:  linkType,
+		})
+	}
+}
+
+func hasRel(rel string, value string) bool {
+	for _, r := range strings.Fields(rel) {
+		if strings.EqualFold(r, value) {
+			return true
+		}
+	}
+
+	return false
+}
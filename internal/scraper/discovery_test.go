@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const discoveryFeed = `<rss><channel><title>Blog feed</title></channel></rss>`
+
+func TestDiscoverAdvertisedFeeds(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!DOCTYPE html>
+<html>
+<head>
+	<link rel="stylesheet" href="/style.css">
+	<link rel="alternate" type="application/rss+xml" title="RSS" href="/blog/rss.xml">
+	<link rel="alternate" type="application/atom+xml" title="Atom" href="https://example.com/atom.xml">
+	<link rel="alternate" type="text/html" hreflang="fr" href="/fr/">
+</head>
+<body></body>
+</html>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scraper := Scraper{}
+	candidates, err := scraper.Discover(context.Background(), server.URL+"/blog/")
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(candidates) != 2 {
+		t.Fatalf("Invalid number of candidates expected 2 got %d", len(candidates))
+	}
+
+	if candidates[0].Url != server.URL+"/blog/rss.xml" {
+		t.Fatalf("Invalid candidate URL: got '%s'", candidates[0].Url)
+	}
+
+	if candidates[1].Url != "https://example.com/atom.xml" || candidates[1].Type != "application/atom+xml" {
+		t.Fatalf("Invalid candidate: got %+v", candidates[1])
+	}
+}
+
+func TestDiscoverCommonPaths(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Blog</title></head></html>`))
+	})
+	mux.HandleFunc("/rss.xml", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(discoveryFeed))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scraper := Scraper{}
+	candidates, err := scraper.Discover(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].Url != server.URL+"/rss.xml" {
+		t.Fatalf("Invalid candidates: got %+v", candidates)
+	}
+
+	if candidates[0].Title != "Blog feed" {
+		t.Fatalf("Invalid title: expected 'Blog feed' got '%s'", candidates[0].Title)
+	}
+
+	candidates, err = scraper.Discover(context.Background(), server.URL+"/rss.xml")
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(candidates) != 1 || candidates[0].Url != server.URL+"/rss.xml" {
+		t.Fatalf("Expected feed URL to be its own candidate, got %+v", candidates)
+	}
+}
@@ -0,0 +1,51 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+// savePostExtras stores an item's categories and enclosures. Both are
+// append-only, so saving them again for an edited post is harmless; edits
+// clear the categories first so removed ones don't linger.
+func savePostExtras(ctx context.Context, q *database.Queries, postID uuid.UUID, item FeedItem) {
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+
+		err := q.CreatePostCategory(ctx, database.CreatePostCategoryParams{
+			PostID: postID,
+			Name:   category,
+		})
+		if err != nil {
+			log.Printf("Failed to save category '%s' of Post: %v\n", category, err)
+		}
+	}
+
+	for _, enclosure := range item.Enclosures {
+		if enclosure.Url == "" {
+			continue
+		}
+
+		err := q.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{
+			ID:     uuid.New(),
+			PostID: postID,
+			Url:    enclosure.Url,
+			Type:   stringToNullString(enclosure.Type),
+			Length: sql.NullInt64{
+				Int64: enclosure.Length,
+				Valid: enclosure.Length > 0,
+			},
+		})
+		if err != nil {
+			log.Printf("Failed to save enclosure %s of Post: %v\n", enclosure.Url, err)
+		}
+	}
+}
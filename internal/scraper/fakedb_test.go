@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// fakeDB stands in for Postgres in tests of code that runs queries. Each
+// query is answered by the handler registered for its sqlc name, and the
+// columns it returns are read from its RETURNING or SELECT list.
+type fakeDB struct {
+	mu        sync.Mutex
+	handlers  map[string]fakeHandler
+	calls     []string
+	commits   int
+	rollbacks int
+}
+
+type fakeRow map[string]driver.Value
+
+type fakeHandler func(query string, args []driver.Value) ([]fakeRow, error)
+
+var (
+	fakeDBs     sync.Map
+	fakeDBCount atomic.Int64
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	db := &fakeDB{handlers: map[string]fakeHandler{}}
+
+	name := fmt.Sprintf("fakedb-%d", fakeDBCount.Add(1))
+	fakeDBs.Store(name, db)
+
+	conn, err := sql.Open("fakedb", name)
+	if err != nil {
+		t.Fatalf("Failed to open fake DB: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return db, conn
+}
+
+func (db *fakeDB) handle(name string, handler fakeHandler) {
+	db.handlers[name] = handler
+}
+
+func (db *fakeDB) run(query string, args []driver.NamedValue) ([]fakeRow, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := queryName(query)
+	db.calls = append(db.calls, name)
+
+	handler, ok := db.handlers[name]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query %s", name)
+	}
+
+	values := []driver.Value{}
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	return handler(query, values)
+}
+
+// queryName reads the name from the "-- name: Name :kind" header sqlc puts
+// at the start of each query.
+func queryName(query string) string {
+	fields := strings.Fields(strings.TrimPrefix(query, "-- name:"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return fields[0]
+}
+
+func queryColumns(query string) []string {
+	list := ""
+	if i := strings.LastIndex(query, "RETURNING "); i >= 0 {
+		list = query[i+len("RETURNING "):]
+	} else if i := strings.Index(query, "SELECT "); i >= 0 {
+		list = query[i+len("SELECT "):]
+		list = list[:strings.Index(list, " FROM")]
+	}
+
+	columns := []string{}
+	for _, column := range strings.Split(list, ",") {
+		columns = append(columns, strings.TrimSpace(column))
+	}
+
+	return columns
+}
+
+// insertedRow maps the arguments of an INSERT to the columns it lists.
+func insertedRow(query string, args []driver.Value) fakeRow {
+	list := query[strings.Index(query, "(")+1 : strings.Index(query, ")")]
+
+	row := fakeRow{}
+	for i, column := range strings.Split(list, ",") {
+		row[strings.TrimSpace(column)] = args[i]
+	}
+
+	return row
+}
+
+var errUniqueViolation = &pq.Error{Code: "23505"}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown fake DB %s", name)
+	}
+
+	return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(len(rows)), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeRows{columns: queryColumns(query), rows: rows}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    []fakeRow
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	for i, column := range r.columns {
+		dest[i] = r.rows[0][column]
+	}
+	r.rows = r.rows[1:]
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+// fetchAttempt collects what happened during a single fetch of a feed so it
+// can be written to the fetch_attempts log.
+type fetchAttempt struct {
+	StartedAt     time.Time
+	StatusCode    int
+	Bytes         int
+	ItemsFound    int
+	ItemsInserted int
+	// Set when the feed answered with a permanent redirect.
+	PermanentURL string
+	// Fixes needed to parse a malformed feed.
+	Recoveries []string
+}
+
+func (s *Scraper) recordAttempt(feed database.Feed, attempt fetchAttempt, fetchErr error) {
+	errorMessage := sql.NullString{}
+	if fetchErr != nil {
+		errorMessage.String = fetchErr.Error()
+		errorMessage.Valid = true
+	}
+
+	recoveries := attempt.Recoveries
+	if recoveries == nil {
+		recoveries = []string{}
+	}
+
+	_, err := s.DB.CreateFetchAttempt(context.Background(), database.CreateFetchAttemptParams{
+		ID:          uuid.New(),
+		FeedID:      feed.ID,
+		AttemptedAt: attempt.StartedAt,
+		StatusCode: sql.NullInt32{
+			Int32: int32(attempt.StatusCode),
+			Valid: attempt.StatusCode != 0,
+		},
+		DurationMs:    int32(time.Since(attempt.StartedAt).Milliseconds()),
+		Bytes:         int32(attempt.Bytes),
+		ItemsFound:    int32(attempt.ItemsFound),
+		ItemsInserted: int32(attempt.ItemsInserted),
+		Error:         errorMessage,
+		Recoveries:    recoveries,
+	})
+	if err != nil {
+		log.Printf("Error recording fetch attempt for %s: %v\n", feed.Url, err)
+	}
+}
@@ -0,0 +1,265 @@
+package scraper
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Fetcher sends the scraper's outbound HTTP requests, following redirects.
+// *http.Client satisfies it, so tests can inject a client pointed at an
+// httptest server. Its CheckRedirect must be set to CheckRedirect for
+// redirect targets to be checked against robots.txt.
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+const maxRedirects = 10
+
+type urlCheckKey struct{}
+
+// withURLCheck makes CheckRedirect refuse redirects of ctx's request to the
+// URLs check rejects.
+func withURLCheck(ctx context.Context, check func(*url.URL) error) context.Context {
+	return context.WithValue(ctx, urlCheckKey{}, check)
+}
+
+// CheckRedirect is an http.Client CheckRedirect that stops after 10
+// redirects, like the default one, and refuses redirects to URLs the scraper
+// isn't allowed to fetch.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	if check, ok := req.Context().Value(urlCheckKey{}).(func(*url.URL) error); ok {
+		return check(req.URL)
+	}
+
+	return nil
+}
+
+var ErrResponseTooLarge = errors.New("response body too large")
+
+const (
+	DefaultUserAgent = "gorss/1.0 (+https://github.com/PFrek/gorss)"
+
+	defaultConnectTimeout = 10 * time.Second
+	defaultReadTimeout    = 30 * time.Second
+	defaultMaxBodySize    = 10 << 20 // 10 MiB
+)
+
+type FetcherConfig struct {
+	// Time allowed to establish a connection, including the TLS handshake.
+	ConnectTimeout time.Duration
+	// Time allowed for a whole request, until the last byte of the body is
+	// read. Also bounds the wait for the response headers.
+	ReadTimeout time.Duration
+	// Maximum size of a decoded response body, in bytes.
+	MaxBodySize int64
+	UserAgent   string
+	// Outbound proxy, e.g. http://proxy.internal:3128. When empty, the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply.
+	ProxyURL string
+}
+
+// HTTPFetcher is the default Fetcher. It bounds how long and how much a
+// single feed can make the scraper wait for and read, and decodes gzip and
+// brotli compressed responses itself.
+type HTTPFetcher struct {
+	client      http.Client
+	maxBodySize int64
+	userAgent   string
+}
+
+// NewHTTPFetcher returns a fetcher for config, with zero fields replaced by
+// their defaults.
+func NewHTTPFetcher(config FetcherConfig) (*HTTPFetcher, error) {
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = defaultConnectTimeout
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = defaultReadTimeout
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultMaxBodySize
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyUrl, err := url.Parse(config.ProxyURL)
+		if err != nil || proxyUrl.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", config.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyUrl)
+	}
+
+	dialer := net.Dialer{
+		Timeout:   config.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &HTTPFetcher{
+		client: http.Client{
+			Transport: &http.Transport{
+				Proxy:                 proxy,
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   config.ConnectTimeout,
+				ResponseHeaderTimeout: config.ReadTimeout,
+				IdleConnTimeout:       90 * time.Second,
+				MaxIdleConns:          100,
+				// Accept-Encoding is set and decoded by Do, to add brotli.
+				DisableCompression: true,
+			},
+			Timeout:       config.ReadTimeout,
+			CheckRedirect: CheckRedirect,
+		},
+		maxBodySize: config.MaxBodySize,
+		userAgent:   config.UserAgent,
+	}, nil
+}
+
+// Do sends req and returns the response with its body decoded. Reading more
+// than the maximum body size fails with ErrResponseTooLarge.
+func (f *HTTPFetcher) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", f.userAgent)
+	}
+	req.Header.Set("Accept-Encoding", "gzip, br")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := decodeBody(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	resp.Body = readCloser{
+		Reader: &limitedReader{reader: body, remaining: f.maxBodySize},
+		Closer: resp.Body,
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	return resp, nil
+}
+
+func (f *HTTPFetcher) UserAgent() string {
+	return f.userAgent
+}
+
+// decodeBody returns a reader decoding resp's body. Responses that can't have
+// a body, such as a 304, are left alone whatever their Content-Encoding.
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	if resp.StatusCode < 200 || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return resp.Body, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		return &gzipReader{body: resp.Body}, nil
+	case "br":
+		return brotli.NewReader(resp.Body), nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding: %s", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+// gzipReader only reads the gzip header once the body is read, so that an
+// empty body, as error responses often have, reads as empty instead of
+// failing.
+type gzipReader struct {
+	body   io.Reader
+	reader *gzip.Reader
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		reader, err := gzip.NewReader(r.body)
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid gzip response: %v", err)
+		}
+		r.reader = reader
+	}
+
+	return r.reader.Read(p)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitedReader is like io.LimitedReader, but fails instead of silently
+// truncating the body once the limit is exceeded.
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+
+	// Read one byte past the limit to tell an exact fit from an overflow.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), ErrResponseTooLarge
+	}
+
+	return n, err
+}
+
+var defaultFetcher, _ = NewHTTPFetcher(FetcherConfig{})
+
+func (s *Scraper) fetcher() Fetcher {
+	if s.Fetcher == nil {
+		return defaultFetcher
+	}
+
+	return s.Fetcher
+}
+
+// do sends one of the scraper's outbound requests, refusing with a
+// RobotsDisallowedError the ones robots.txt doesn't allow, whether requested
+// directly or redirected to.
+func (s *Scraper) do(req *http.Request) (*http.Response, error) {
+	err := s.checkRobots(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(withURLCheck(req.Context(), s.checkRobots))
+	return s.fetcher().Do(req)
+}
@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+const fetcherTestBody = `<rss><channel><title>Compressed</title></channel></rss>`
+
+func TestHTTPFetcherDecoding(t *testing.T) {
+	var userAgent string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, req *http.Request) {
+		userAgent = req.Header.Get("User-Agent")
+		w.Write([]byte(fetcherTestBody))
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(fetcherTestBody))
+		writer.Close()
+	})
+	mux.HandleFunc("/br", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		writer := brotli.NewWriter(w)
+		writer.Write([]byte(fetcherTestBody))
+		writer.Close()
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher, err := NewHTTPFetcher(FetcherConfig{})
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	for _, path := range []string{"/plain", "/gzip", "/br"} {
+		req, _ := http.NewRequest("GET", server.URL+path, nil)
+		resp, err := fetcher.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		if string(body) != fetcherTestBody {
+			t.Fatalf("%s: invalid body: got %q", path, body)
+		}
+	}
+
+	if userAgent != DefaultUserAgent {
+		t.Fatalf("Invalid User-Agent: expected '%s' got '%s'", DefaultUserAgent, userAgent)
+	}
+}
+
+func TestHTTPFetcherEmptyGzipBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/not-modified", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNotModified)
+	})
+	mux.HandleFunc("/unavailable", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte(fetcherTestBody))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher, err := NewHTTPFetcher(FetcherConfig{})
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	cases := []struct {
+		path   string
+		status int
+		valid  bool
+	}{
+		{"/not-modified", http.StatusNotModified, true},
+		{"/unavailable", http.StatusServiceUnavailable, true},
+		{"/invalid", http.StatusOK, false},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest("GET", server.URL+c.path, nil)
+		resp, err := fetcher.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", c.path, err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Fatalf("%s: expected status %d got %d", c.path, c.status, resp.StatusCode)
+		}
+		if (err == nil) != c.valid {
+			t.Fatalf("%s: expected valid %v got %v", c.path, c.valid, err)
+		}
+		if c.valid && len(body) != 0 {
+			t.Fatalf("%s: expected an empty body got %q", c.path, body)
+		}
+	}
+}
+
+func TestHTTPFetcherMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(strings.Repeat("a", 2048)))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	for _, c := range []struct {
+		maxBodySize int64
+		expected    error
+	}{
+		{2048, nil},
+		{2047, ErrResponseTooLarge},
+	} {
+		fetcher, _ := NewHTTPFetcher(FetcherConfig{MaxBodySize: c.maxBodySize})
+
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := fetcher.Do(req)
+		if err != nil {
+			t.Fatalf("Failed: %v", err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !errors.Is(err, c.expected) {
+			t.Fatalf("Max %d: expected error %v got %v", c.maxBodySize, c.expected, err)
+		}
+		if err == nil && len(body) != 2048 {
+			t.Fatalf("Max %d: invalid body length %d", c.maxBodySize, len(body))
+		}
+	}
+}
+
+func TestHTTPFetcherTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	fetcher, _ := NewHTTPFetcher(FetcherConfig{ReadTimeout: 20 * time.Millisecond})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	_, err := fetcher.Do(req)
+	if err == nil {
+		t.Fatalf("Expected a timeout error")
+	}
+}
+
+func TestNewHTTPFetcherInvalidProxy(t *testing.T) {
+	_, err := NewHTTPFetcher(FetcherConfig{ProxyURL: "not a url"})
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid proxy URL")
+	}
+}
@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"github.com/PFrek/gorss/internal/database"
+)
+
+// contentHashVersion identifies the scheme contentHash implements. Version 1
+// hashed only the title, link and description, as in the backfill of
+// 011_posts_guid.sql.
+const contentHashVersion = 2
+
+// contentHash fingerprints the parts of an item that are stored in a post.
+func contentHash(item FeedItem) string {
+	description := ""
+	if item.Description != nil {
+		description = *item.Description
+	}
+
+	data := item.Title + "\n" + item.Link + "\n" + description
+
+	// Only included when present, so items without full content keep the
+	// hash they had before it was stored.
+	if item.Content != nil {
+		data += "\n" + *item.Content
+	}
+
+	return hashString(data)
+}
+
+// legacyContentHashes returns the hashes the item may have had under version
+// 1. JSON Feed items then used their content as description.
+func legacyContentHashes(item FeedItem) []string {
+	hashes := []string{}
+	if item.Description != nil {
+		hashes = append(hashes, hashString(item.Title+"\n"+item.Link+"\n"+*item.Description))
+	} else {
+		hashes = append(hashes, hashString(item.Title+"\n"+item.Link+"\n"))
+	}
+	if item.Content != nil {
+		hashes = append(hashes, hashString(item.Title+"\n"+item.Link+"\n"+*item.Content))
+	}
+
+	return hashes
+}
+
+func hashString(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// itemGUID identifies an item within its feed: the publisher's GUID when
+// present, then its link, and finally the hash of its content for items that
+// have neither.
+func itemGUID(item FeedItem) string {
+	guid := strings.TrimSpace(item.GUID)
+	if guid != "" {
+		return guid
+	}
+
+	link := strings.TrimSpace(item.Link)
+	if link != "" {
+		return link
+	}
+
+	return contentHash(item)
+}
+
+// adoptLegacyGuids gives the posts migration 011 stored with their link as
+// GUID the publisher's GUID, so that their items aren't inserted again. It
+// only runs for the feeds flagged by migration 020, on their first fetch
+// since, as their current items are then all adopted.
+func (s *Scraper) adoptLegacyGuids(feed database.Feed, data FeedData) {
+	if !feed.LegacyGuids {
+		return
+	}
+
+	for _, item := range data.Items {
+		guid := itemGUID(item)
+		link := strings.TrimSpace(item.Link)
+		if link == "" || link == guid {
+			continue
+		}
+
+		err := s.DB.AdoptLegacyPostGuid(context.Background(), database.AdoptLegacyPostGuidParams{
+			Guid:       guid,
+			FeedID:     feed.ID,
+			LegacyGuid: link,
+		})
+		if err != nil {
+			log.Printf("Failed to adopt legacy GUID of Post %s: %v\n", item.Title, err)
+			return
+		}
+	}
+
+	err := s.DB.ClearFeedLegacyGuids(context.Background(), feed.ID)
+	if err != nil {
+		log.Printf("Error clearing legacy GUIDs flag of %s: %v\n", feed.Url, err)
+	}
+}
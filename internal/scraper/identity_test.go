@@ -0,0 +1,173 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"testing"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestItemGUID(t *testing.T) {
+	description := "Entry description"
+
+	cases := []struct {
+		name     string
+		item     FeedItem
+		expected string
+	}{
+		{
+			name:     "publisher guid",
+			item:     FeedItem{GUID: " tag:example.com,2024:1 ", Link: "https://example.com/1"},
+			expected: "tag:example.com,2024:1",
+		},
+		{
+			name:     "link fallback",
+			item:     FeedItem{Link: "https://example.com/1"},
+			expected: "https://example.com/1",
+		},
+		{
+			name:     "content hash fallback",
+			item:     FeedItem{Title: "No link", Description: &description},
+			expected: contentHash(FeedItem{Title: "No link", Description: &description}),
+		},
+	}
+
+	for _, c := range cases {
+		guid := itemGUID(c.item)
+		if guid != c.expected {
+			t.Fatalf("%s: expected '%s' got '%s'", c.name, c.expected, guid)
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	description := "Entry description"
+	edited := "Edited description"
+
+	original := FeedItem{GUID: "1", Title: "Entry", Link: "https://example.com/1", Description: &description}
+	same := FeedItem{GUID: "other", Title: "Entry", Link: "https://example.com/1", Description: &description}
+	changed := FeedItem{GUID: "1", Title: "Entry", Link: "https://example.com/1", Description: &edited}
+
+	if contentHash(original) != contentHash(same) {
+		t.Fatalf("Expected hash to ignore the guid")
+	}
+
+	if contentHash(original) == contentHash(changed) {
+		t.Fatalf("Expected hash to change with the description")
+	}
+
+	// Matches the SQL backfill: encode(sha256(convert_to('Entry' || E'\n' || 'https://example.com/1' || E'\n' || 'Entry description', 'UTF8')), 'hex')
+	expected := "292f8d2e6985fdd9b2e15216f96f7eee3f1bdf637e9b1b0d5cf02a2b54aaa682"
+	if contentHash(original) != expected {
+		t.Fatalf("Invalid hash: expected '%s' got '%s'", expected, contentHash(original))
+	}
+}
+
+// fakePosts keeps the posts table of a fakeDB.
+type fakePosts struct {
+	rows []fakeRow
+}
+
+func handlePosts(db *fakeDB) *fakePosts {
+	posts := &fakePosts{}
+
+	db.handle("CreatePost", func(query string, args []driver.Value) ([]fakeRow, error) {
+		row := insertedRow(query, args)
+		if posts.find(row["feed_id"], row["guid"]) != nil {
+			return nil, errUniqueViolation
+		}
+
+		posts.rows = append(posts.rows, row)
+		return []fakeRow{row}, nil
+	})
+
+	db.handle("GetPostByGuid", func(query string, args []driver.Value) ([]fakeRow, error) {
+		row := posts.find(args[0], args[1])
+		if row == nil {
+			return nil, nil
+		}
+
+		return []fakeRow{row}, nil
+	})
+
+	db.handle("AdoptLegacyPostGuid", func(query string, args []driver.Value) ([]fakeRow, error) {
+		row := posts.find(args[1], args[2])
+		if row == nil || posts.find(args[1], args[0]) != nil {
+			return nil, nil
+		}
+
+		row["guid"] = args[0]
+		return []fakeRow{row}, nil
+	})
+
+	return posts
+}
+
+func (p *fakePosts) find(feedID driver.Value, guid driver.Value) fakeRow {
+	for _, row := range p.rows {
+		if row["feed_id"] == feedID && row["guid"] == guid {
+			return row
+		}
+	}
+
+	return nil
+}
+
+func TestAdoptLegacyGuids(t *testing.T) {
+	db, conn := newFakeDB(t)
+	posts := handlePosts(db)
+
+	cleared := false
+	db.handle("ClearFeedLegacyGuids", func(query string, args []driver.Value) ([]fakeRow, error) {
+		cleared = true
+		return nil, nil
+	})
+
+	feed := database.Feed{ID: uuid.New(), LegacyGuids: true}
+	link := "https://example.com/1"
+	description := "Entry description"
+
+	// Stored before GUIDs were tracked: migration 011 set its GUID to its URL
+	// and hashed its title, URL and description.
+	sum := sha256.Sum256([]byte("Entry\n" + link + "\n" + description))
+	posts.rows = append(posts.rows, storedPost(feed.ID, link, hex.EncodeToString(sum[:]), 1))
+
+	data := FeedData{
+		Items: []FeedItem{
+			{
+				Title:       "Entry",
+				Link:        link,
+				GUID:        "tag:example.com,2024:1",
+				Description: &description,
+				PubDate:     "Wed, 05 Jun 2024 12:00:00 GMT",
+			},
+		},
+	}
+
+	scraper := Scraper{DB: database.New(conn), Conn: conn}
+	scraper.adoptLegacyGuids(feed, data)
+	inserted := scraper.processFeed(data, feed.ID)
+
+	if inserted != 0 || len(posts.rows) != 1 {
+		t.Fatalf("Expected existing post to be kept, got %d inserted and %d stored", inserted, len(posts.rows))
+	}
+
+	if posts.rows[0]["guid"] != "tag:example.com,2024:1" {
+		t.Fatalf("Expected publisher GUID to be adopted, got %v", posts.rows[0]["guid"])
+	}
+
+	if !cleared {
+		t.Fatalf("Expected the feed's legacy GUIDs flag to be cleared")
+	}
+
+	// Once adopted, fetches no longer look for legacy posts.
+	db.calls = nil
+	feed.LegacyGuids = false
+	scraper.adoptLegacyGuids(feed, data)
+	if len(db.calls) != 0 {
+		t.Fatalf("Expected no queries for a feed without legacy GUIDs, got %v", db.calls)
+	}
+}
@@ -0,0 +1,161 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/1"
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONFeedAttachment struct {
+	Url         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	Url           string               `json:"url"`
+	ExternalUrl   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedHub struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+type JSONFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	FeedUrl string         `json:"feed_url"`
+	Hubs    []JSONFeedHub  `json:"hubs"`
+	Items   []JSONFeedItem `json:"items"`
+}
+
+func (item JSONFeedItem) toFeedItem() FeedItem {
+	var content *string
+	if item.ContentHTML != "" {
+		content = new(string)
+		*content = item.ContentHTML
+	} else if item.ContentText != "" {
+		content = new(string)
+		*content = item.ContentText
+	}
+
+	description := content
+	if item.Summary != "" {
+		description = new(string)
+		*description = item.Summary
+	}
+
+	// JSON Feed 1.1 replaced author with authors.
+	authors := []string{}
+	for _, author := range item.Authors {
+		authors = append(authors, author.Name)
+	}
+	if len(authors) == 0 && item.Author != nil {
+		authors = append(authors, item.Author.Name)
+	}
+
+	enclosures := []Enclosure{}
+	for _, attachment := range item.Attachments {
+		enclosures = append(enclosures, Enclosure{
+			Url:    attachment.Url,
+			Type:   attachment.MimeType,
+			Length: attachment.SizeInBytes,
+		})
+	}
+
+	link := item.Url
+	if link == "" {
+		link = item.ExternalUrl
+	}
+
+	return FeedItem{
+		GUID:        item.ID,
+		Title:       item.Title,
+		Link:        link,
+		Description: description,
+		PubDate:     item.DatePublished,
+		Content:     content,
+		Author:      strings.Join(authors, ", "),
+		Categories:  item.Tags,
+		Enclosures:  enclosures,
+	}
+}
+
+func (feed JSONFeed) toFeedData() FeedData {
+	feedData := FeedData{
+		Title:   feed.Title,
+		SelfURL: feed.FeedUrl,
+	}
+	for _, hub := range feed.Hubs {
+		if strings.EqualFold(hub.Type, "WebSub") {
+			feedData.HubURL = hub.Url
+			break
+		}
+	}
+
+	for _, item := range feed.Items {
+		feedData.Items = append(feedData.Items, item.toFeedItem())
+	}
+
+	return feedData
+}
+
+// isJSONFeed reports whether a response looks like a JSON Feed document,
+// either by its declared media type or by the version field of a JSON body.
+func isJSONFeed(contentType string, data []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/feed+json" {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+
+	header := struct {
+		Version string `json:"version"`
+	}{}
+	err := json.Unmarshal(trimmed, &header)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(header.Version, jsonFeedVersionPrefix)
+}
+
+func parseJSONFeed(jsonData io.Reader) (FeedData, error) {
+	var jsonFeed JSONFeed
+
+	decoder := json.NewDecoder(jsonData)
+	err := decoder.Decode(&jsonFeed)
+	if err != nil {
+		return FeedData{}, err
+	}
+
+	if !strings.HasPrefix(jsonFeed.Version, jsonFeedVersionPrefix) {
+		return FeedData{}, fmt.Errorf("unsupported JSON Feed version: %s", jsonFeed.Version)
+	}
+
+	return jsonFeed.toFeedData(), nil
+}
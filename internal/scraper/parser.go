@@ -0,0 +1,153 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+// FeedData is the normalized feed model every Parser produces, and the only
+// representation of a feed that processFeed consumes.
+type FeedData struct {
+	Title string
+	Items []FeedItem
+
+	// Polling hints advertised by the publisher.
+	TTL            time.Duration
+	UpdateInterval time.Duration
+	SkipHours      []int
+	SkipDays       []time.Weekday
+
+	// WebSub hub advertised by the feed and the topic URL to subscribe to.
+	HubURL  string
+	SelfURL string
+
+	// Fixes applied to parse a malformed document, see recoverXML.
+	Recoveries []string
+}
+
+type FeedItem struct {
+	// Identifier assigned by the publisher, e.g. RSS <guid> or Atom <id>.
+	GUID        string
+	Title       string
+	Link        string
+	Description *string
+	PubDate     string
+	// Full content, when published separately from the description.
+	Content     *string
+	Author      string
+	Categories  []string
+	CommentsURL string
+	Enclosures  []Enclosure
+	// Podcast metadata from the itunes: and media: extensions. Zero values
+	// mean the feed did not provide them; Duration is in seconds.
+	Duration int
+	Episode  int
+	Season   int
+	ImageURL string
+	Explicit *bool
+}
+
+// Enclosure is a media file attached to an item, e.g. a podcast episode.
+type Enclosure struct {
+	Url    string
+	Type   string
+	Length int64
+}
+
+// Parser turns a fetched document into FeedData. Detect sniffs the response's
+// Content-Type header and body and reports whether Parse understands it. The
+// registry converts documents to UTF-8 before handing them to a Parser.
+type Parser interface {
+	Detect(contentType string, data []byte) bool
+	Parse(data []byte) (FeedData, error)
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported feed format")
+
+// ParserRegistry dispatches a document to the first registered Parser that
+// detects it, so parsers registered earlier take precedence.
+type ParserRegistry struct {
+	parsers []Parser
+}
+
+func NewParserRegistry(parsers ...Parser) *ParserRegistry {
+	return &ParserRegistry{
+		parsers: parsers,
+	}
+}
+
+// DefaultParserRegistry returns a registry with the built-in formats.
+func DefaultParserRegistry() *ParserRegistry {
+	return NewParserRegistry(RSSParser{}, JSONFeedParser{})
+}
+
+func (r *ParserRegistry) Register(parser Parser) {
+	r.parsers = append(r.parsers, parser)
+}
+
+func (r *ParserRegistry) Parse(contentType string, data []byte) (FeedData, error) {
+	data = toUTF8(contentType, data)
+
+	for _, parser := range r.parsers {
+		if parser.Detect(contentType, data) {
+			return parser.Parse(data)
+		}
+	}
+
+	return FeedData{}, ErrUnsupportedFormat
+}
+
+// RSSParser handles the XML syndication formats: RSS 2.0, RSS 1.0 (RDF) and
+// Atom 1.0.
+type RSSParser struct{}
+
+func (RSSParser) Detect(contentType string, data []byte) bool {
+	root, err := xmlRootElement(data)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case root.Name.Local == "rss":
+		return true
+	case root.Name.Space == atomNamespace && root.Name.Local == "feed":
+		return true
+	case root.Name.Space == rdfNamespace && root.Name.Local == "RDF":
+		return true
+	}
+
+	return false
+}
+
+func (RSSParser) Parse(data []byte) (FeedData, error) {
+	return parseXML(bytes.NewReader(data))
+}
+
+type JSONFeedParser struct{}
+
+func (JSONFeedParser) Detect(contentType string, data []byte) bool {
+	return isJSONFeed(contentType, data)
+}
+
+func (JSONFeedParser) Parse(data []byte) (FeedData, error) {
+	return parseJSONFeed(bytes.NewReader(data))
+}
+
+func xmlRootElement(data []byte) (xml.StartElement, error) {
+	data, _ = stripLeadingJunk(data)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
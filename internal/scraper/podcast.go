@@ -0,0 +1,171 @@
+package scraper
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type MediaContent struct {
+	Url      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize int64  `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+type MediaThumbnail struct {
+	Url string `xml:"url,attr"`
+}
+
+// PodcastExtensions holds the itunes: and media: elements of an item. It is
+// embedded in both RSSItem and AtomEntry, ahead of their own fields: those
+// match elements of any namespace, so the namespaced titles, authors and
+// descriptions must be claimed here first or they would replace the item's.
+type PodcastExtensions struct {
+	ITunesTitle        string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	ITunesAuthor       string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	ITunesSummary      string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	MediaTitle         string           `xml:"http://search.yahoo.com/mrss/ title"`
+	MediaDescription   string           `xml:"http://search.yahoo.com/mrss/ description"`
+	ITunesDuration     string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode      string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesSeason       string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	ITunesImage        *ITunesImage     `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	ITunesExplicit     string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+	MediaContents      []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroupContents []MediaContent   `xml:"http://search.yahoo.com/mrss/ group>content"`
+	MediaThumbnails    []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// apply copies the podcast metadata into item. Media contents are added as
+// enclosures unless the item already has an enclosure with the same URL.
+// The iTunes author and summary stand in for a missing author and
+// description.
+func (ext PodcastExtensions) apply(item *FeedItem) {
+	if item.Author == "" {
+		item.Author = strings.TrimSpace(ext.ITunesAuthor)
+	}
+	if item.Description == nil && strings.TrimSpace(ext.ITunesSummary) != "" {
+		item.Description = &ext.ITunesSummary
+	}
+
+	item.Duration = parseITunesDuration(ext.ITunesDuration)
+	item.Episode = parsePositiveInt(ext.ITunesEpisode)
+	item.Season = parsePositiveInt(ext.ITunesSeason)
+	item.Explicit = parseExplicit(ext.ITunesExplicit)
+
+	if ext.ITunesImage != nil {
+		item.ImageURL = strings.TrimSpace(ext.ITunesImage.Href)
+	}
+	if item.ImageURL == "" && len(ext.MediaThumbnails) > 0 {
+		item.ImageURL = strings.TrimSpace(ext.MediaThumbnails[0].Url)
+	}
+
+	contents := []MediaContent{}
+	contents = append(contents, ext.MediaContents...)
+	contents = append(contents, ext.MediaGroupContents...)
+	for _, content := range contents {
+		if content.Url == "" || hasEnclosure(item.Enclosures, content.Url) {
+			continue
+		}
+
+		contentType := content.Type
+		if contentType == "" {
+			contentType = content.Medium
+		}
+
+		item.Enclosures = append(item.Enclosures, Enclosure{
+			Url:    content.Url,
+			Type:   contentType,
+			Length: content.FileSize,
+		})
+
+		if item.Duration == 0 {
+			item.Duration = parseITunesDuration(content.Duration)
+		}
+	}
+}
+
+func hasEnclosure(enclosures []Enclosure, url string) bool {
+	for _, enclosure := range enclosures {
+		if enclosure.Url == url {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseITunesDuration returns the duration in seconds of an itunes:duration
+// value, which is either a number of seconds or an HH:MM:SS / MM:SS clock.
+// Invalid values yield 0.
+func parseITunesDuration(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	seconds := 0
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+	for _, part := range parts {
+		// Fractional seconds are allowed but not kept.
+		part, _, _ = strings.Cut(part, ".")
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+
+	return seconds
+}
+
+func parsePositiveInt(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return n
+}
+
+// parseExplicit reads itunes:explicit. Unknown values leave it unset.
+func parseExplicit(value string) *bool {
+	var explicit bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "explicit":
+		explicit = true
+	case "no", "false", "clean":
+		explicit = false
+	default:
+		return nil
+	}
+
+	return &explicit
+}
+
+func intToNullInt32(value int) sql.NullInt32 {
+	return sql.NullInt32{
+		Int32: int32(value),
+		Valid: value > 0,
+	}
+}
+
+func boolPtrToNullBool(value *bool) sql.NullBool {
+	if value == nil {
+		return sql.NullBool{}
+	}
+
+	return sql.NullBool{
+		Bool:  *value,
+		Valid: true,
+	}
+}
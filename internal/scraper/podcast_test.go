@@ -0,0 +1,209 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseXMLPodcastExtensions(t *testing.T) {
+	xml := `<rss version="2.0"
+	xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+	xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+	<item>
+		<title>Episode 3</title>
+		<link>https://example.com/episode-3</link>
+		<enclosure url="https://example.com/episode-3.mp3" type="audio/mpeg" length="12345"/>
+		<itunes:duration>1:02:03</itunes:duration>
+		<itunes:episode>3</itunes:episode>
+		<itunes:season>2</itunes:season>
+		<itunes:image href="https://example.com/episode-3.jpg"/>
+		<itunes:explicit>no</itunes:explicit>
+		<media:content url="https://example.com/episode-3.mp3" type="audio/mpeg"/>
+		<media:group>
+			<media:content url="https://example.com/episode-3.mp4" medium="video" fileSize="678"/>
+		</media:group>
+		<pubDate>Wed, 05 Jun 2024 00:00:00 +0000</pubDate>
+	</item>
+</channel>
+</rss>`
+
+	reader := strings.NewReader(xml)
+
+	feedData, err := parseXML(reader)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	item := feedData.Items[0]
+	if item.Duration != 3723 {
+		t.Fatalf("Invalid duration: expected 3723 got %d", item.Duration)
+	}
+
+	if item.Episode != 3 || item.Season != 2 {
+		t.Fatalf("Invalid episode/season: got %d/%d", item.Episode, item.Season)
+	}
+
+	if item.ImageURL != "https://example.com/episode-3.jpg" {
+		t.Fatalf("Invalid image URL: got '%s'", item.ImageURL)
+	}
+
+	if item.Explicit == nil || *item.Explicit {
+		t.Fatalf("Invalid explicit flag: got %v", item.Explicit)
+	}
+
+	if len(item.Enclosures) != 2 {
+		t.Fatalf("Invalid number of enclosures expected 2 got %d", len(item.Enclosures))
+	}
+
+	enclosure := item.Enclosures[1]
+	if enclosure.Url != "https://example.com/episode-3.mp4" || enclosure.Type != "video" || enclosure.Length != 678 {
+		t.Fatalf("Invalid media enclosure: got %+v", enclosure)
+	}
+}
+
+func TestParseXMLAtomMediaThumbnail(t *testing.T) {
+	xml := `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+	<title>Videos</title>
+	<entry>
+		<id>video-1</id>
+		<title>Video 1</title>
+		<content type="html">Video content</content>
+		<media:group>
+			<media:content url="https://example.com/video-1.mp4" type="video/mp4" duration="95"/>
+			<media:thumbnail url="https://example.com/video-1.jpg"/>
+		</media:group>
+		<media:thumbnail url="https://example.com/video-1-large.jpg"/>
+		<published>2024-06-05T00:00:00Z</published>
+	</entry>
+</feed>`
+
+	reader := strings.NewReader(xml)
+
+	feedData, err := parseXML(reader)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	item := feedData.Items[0]
+	if item.Content == nil || *item.Content != "Video content" {
+		t.Fatalf("Invalid content: got %v", item.Content)
+	}
+
+	if item.ImageURL != "https://example.com/video-1-large.jpg" {
+		t.Fatalf("Invalid image URL: got '%s'", item.ImageURL)
+	}
+
+	if item.Duration != 95 {
+		t.Fatalf("Invalid duration: expected 95 got %d", item.Duration)
+	}
+
+	if len(item.Enclosures) != 1 || item.Enclosures[0].Type != "video/mp4" {
+		t.Fatalf("Invalid enclosures: got %+v", item.Enclosures)
+	}
+}
+
+func TestParseXMLPodcastNamespacedText(t *testing.T) {
+	feeds := map[string]string{
+		"rss": `<rss version="2.0"
+	xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+	xmlns:media="http://search.yahoo.com/mrss/">
+<channel>
+	<title>Podcast</title>
+	<item>
+		<title>Episode 3: The Full Title</title>
+		<itunes:title>Episode 3</itunes:title>
+		<media:title>Episode 3 (video)</media:title>
+		<description>Show notes</description>
+		<media:description>Video description</media:description>
+		<itunes:summary>Summary</itunes:summary>
+		<author>jane@example.com (Jane)</author>
+		<itunes:author>The Podcast Team</itunes:author>
+		<link>https://example.com/episode-3</link>
+	</item>
+</channel>
+</rss>`,
+		"atom": `<feed xmlns="http://www.w3.org/2005/Atom"
+	xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+	xmlns:media="http://search.yahoo.com/mrss/">
+	<title>Podcast</title>
+	<entry>
+		<id>episode-3</id>
+		<title>Episode 3: The Full Title</title>
+		<itunes:title>Episode 3</itunes:title>
+		<media:title>Episode 3 (video)</media:title>
+		<summary>Show notes</summary>
+		<itunes:summary>Summary</itunes:summary>
+		<author><name>jane@example.com (Jane)</name></author>
+		<itunes:author>The Podcast Team</itunes:author>
+		<link href="https://example.com/episode-3"/>
+	</entry>
+</feed>`,
+	}
+
+	for name, xml := range feeds {
+		feedData, err := parseXML(strings.NewReader(xml))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		item := feedData.Items[0]
+		if item.Title != "Episode 3: The Full Title" {
+			t.Fatalf("%s: invalid title: got '%s'", name, item.Title)
+		}
+		if item.Description == nil || *item.Description != "Show notes" {
+			t.Fatalf("%s: invalid description: got %v", name, item.Description)
+		}
+		if item.Author != "jane@example.com (Jane)" {
+			t.Fatalf("%s: invalid author: got '%s'", name, item.Author)
+		}
+	}
+}
+
+func TestParseXMLPodcastITunesFallbacks(t *testing.T) {
+	xml := `<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+	<title>Podcast</title>
+	<item>
+		<title>Episode 4</title>
+		<itunes:summary>Summary</itunes:summary>
+		<itunes:author>The Podcast Team</itunes:author>
+	</item>
+</channel>
+</rss>`
+
+	feedData, err := parseXML(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	item := feedData.Items[0]
+	if item.Description == nil || *item.Description != "Summary" {
+		t.Fatalf("Invalid description: got %v", item.Description)
+	}
+	if item.Author != "The Podcast Team" {
+		t.Fatalf("Invalid author: got '%s'", item.Author)
+	}
+}
+
+func TestParseITunesDuration(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected int
+	}{
+		{"", 0},
+		{"90", 90},
+		{"05:30", 330},
+		{"1:00:00", 3600},
+		{"12:34.5", 754},
+		{"abc", 0},
+		{"1:2:3:4", 0},
+	}
+
+	for _, c := range cases {
+		duration := parseITunesDuration(c.value)
+		if duration != c.expected {
+			t.Fatalf("%q: expected %d got %d", c.value, c.expected, duration)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+)
+
+const (
+	defaultWorkers         = 8
+	defaultHostConcurrency = 2
+	defaultHostDelay       = time.Second
+	// Feeds whose host can't be contacted within this long, e.g. after a
+	// long Retry-After, are rescheduled instead of holding up the cycle.
+	maxHostWait = 30 * time.Second
+)
+
+// RetryAfterError is returned for 429 and 503 responses carrying a
+// Retry-After header. The host is not contacted again before Until.
+type RetryAfterError struct {
+	Status string
+	Until  time.Time
+}
+
+func (e RetryAfterError) Error() string {
+	return fmt.Sprintf("failed HTTP request: status %v, retry after %v", e.Status, e.Until.Format(time.RFC3339))
+}
+
+// parseRetryAfter reads a Retry-After header, which is either a number of
+// seconds or an HTTP date. It reports false for missing or invalid values.
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return time.Time{}, false
+		}
+		return now.Add(min(time.Duration(seconds)*time.Second, maxFetchInterval)), true
+	}
+
+	until, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if until.After(now.Add(maxFetchInterval)) {
+		until = now.Add(maxFetchInterval)
+	}
+
+	return until, true
+}
+
+func retryAfterError(resp *http.Response, now time.Time) (RetryAfterError, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return RetryAfterError{}, false
+	}
+
+	until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok {
+		return RetryAfterError{}, false
+	}
+
+	return RetryAfterError{
+		Status: resp.Status,
+		Until:  until,
+	}, true
+}
+
+// hostLimiter spaces out requests to the same host, across scrape cycles.
+type hostLimiter struct {
+	mu          sync.Mutex
+	nextAllowed map[string]time.Time
+}
+
+// reserve books the next request slot for host, delay after the previous
+// one, and returns when it starts. If that is more than maxHostWait away no
+// slot is booked and false is returned along with when the host is free.
+func (l *hostLimiter) reserve(host string, delay time.Duration, now time.Time) (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.nextAllowed == nil {
+		l.nextAllowed = map[string]time.Time{}
+	}
+
+	start := l.nextAllowed[host]
+	if start.Before(now) {
+		start = now
+	}
+	if start.Sub(now) > maxHostWait {
+		return start, false
+	}
+
+	l.nextAllowed[host] = start.Add(delay)
+	return start, true
+}
+
+// deferUntil keeps host from being contacted before until.
+func (l *hostLimiter) deferUntil(host string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.nextAllowed == nil {
+		l.nextAllowed = map[string]time.Time{}
+	}
+
+	if until.After(l.nextAllowed[host]) {
+		l.nextAllowed[host] = until
+	}
+}
+
+// loadHostDeferrals applies the deferrals other instances recorded for hosts,
+// so a Retry-After one of them received is honored by all.
+func (s *Scraper) loadHostDeferrals(hosts []string) {
+	deferrals, err := s.DB.GetHostDeferrals(context.Background(), hosts)
+	if err != nil {
+		log.Printf("Error getting host deferrals: %v\n", err)
+		return
+	}
+
+	for _, deferral := range deferrals {
+		s.hosts.deferUntil(deferral.Host, deferral.DeferredUntil)
+	}
+}
+
+// deferHost keeps host from being contacted before until, by this instance
+// and, through the database, by the others.
+func (s *Scraper) deferHost(host string, until time.Time) {
+	s.hosts.deferUntil(host, until)
+
+	err := s.DB.DeferHost(context.Background(), database.DeferHostParams{
+		Host:          host,
+		DeferredUntil: until,
+	})
+	if err != nil {
+		log.Printf("Error deferring host %s: %v\n", host, err)
+	}
+}
+
+func feedHost(feed database.Feed) string {
+	parsed, err := url.Parse(feed.Url)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsed.Host)
+}
+
+// fetchAll fetches feeds with at most s.Workers fetches running at once and
+// at most s.HostConcurrency of them against the same host, starting requests
+// to a host at least s.HostDelay apart. These limits apply to each instance
+// on its own, while hosts deferred by a Retry-After are shared by all.
+func (s *Scraper) fetchAll(feeds []database.Feed) {
+	byHost := map[string][]database.Feed{}
+	for _, feed := range feeds {
+		host := feedHost(feed)
+		byHost[host] = append(byHost[host], feed)
+	}
+
+	hosts := []string{}
+	for host := range byHost {
+		hosts = append(hosts, host)
+	}
+	s.loadHostDeferrals(hosts)
+
+	workers := make(chan struct{}, s.workers())
+	var wg sync.WaitGroup
+
+	for host, hostFeeds := range byHost {
+		queue := make(chan database.Feed, len(hostFeeds))
+		for _, feed := range hostFeeds {
+			queue <- feed
+		}
+		close(queue)
+
+		for i := 0; i < min(s.hostConcurrency(), len(hostFeeds)); i++ {
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				for feed := range queue {
+					s.fetchFeedPolitely(host, feed, workers)
+				}
+			}(host)
+		}
+	}
+
+	wg.Wait()
+}
+
+// fetchFeedPolitely fetches a feed once its host's slot comes up and a worker
+// is free, then releases the feed's claim. The host slot is booked first so
+// that waiting for it doesn't hold up a worker other hosts could use. At
+// worst, requests queued for a busy pool then start together, which
+// s.HostConcurrency still bounds.
+func (s *Scraper) fetchFeedPolitely(host string, feed database.Feed, workers chan struct{}) {
+	defer s.releaseClaim(feed)
+
+	now := time.Now().UTC()
+	start, ok := s.hosts.reserve(host, s.hostDelay(), now)
+	if !ok {
+		log.Printf("Host %s unavailable until %v, postponing %s\n", host, start, feed.Url)
+		s.scheduleNextFetch(feed, start)
+		return
+	}
+	s.extendClaim(feed, start)
+	time.Sleep(start.Sub(now))
+
+	workers <- struct{}{}
+	defer func() { <-workers }()
+
+	err := s.fetchFeed(feed)
+
+	var retryAfter RetryAfterError
+	if errors.As(err, &retryAfter) {
+		s.deferHost(host, retryAfter.Until)
+	}
+}
+
+func (s *Scraper) workers() int {
+	if s.Workers <= 0 {
+		return defaultWorkers
+	}
+
+	return s.Workers
+}
+
+func (s *Scraper) hostConcurrency() int {
+	if s.HostConcurrency <= 0 {
+		return defaultHostConcurrency
+	}
+
+	return s.HostConcurrency
+}
+
+func (s *Scraper) hostDelay() time.Duration {
+	if s.HostDelay <= 0 {
+		return defaultHostDelay
+	}
+
+	return s.HostDelay
+}
@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		{"", time.Time{}, false},
+		{"120", now.Add(2 * time.Minute), true},
+		{"-5", time.Time{}, false},
+		{"Wed, 05 Jun 2024 12:30:00 GMT", now.Add(30 * time.Minute), true},
+		{"999999", now.Add(maxFetchInterval), true},
+		{"soon", time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		until, ok := parseRetryAfter(c.value, now)
+		if ok != c.ok || !until.Equal(c.expected) {
+			t.Fatalf("%q: expected %v/%v got %v/%v", c.value, c.expected, c.ok, until, ok)
+		}
+	}
+}
+
+func TestRetryAfterError(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		status int
+		header string
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, "60", true},
+		{http.StatusServiceUnavailable, "60", true},
+		{http.StatusTooManyRequests, "", false},
+		{http.StatusInternalServerError, "60", false},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{
+			StatusCode: c.status,
+			Header:     http.Header{},
+		}
+		if c.header != "" {
+			resp.Header.Set("Retry-After", c.header)
+		}
+
+		err, ok := retryAfterError(resp, now)
+		if ok != c.ok {
+			t.Fatalf("%d %q: expected %v got %v", c.status, c.header, c.ok, ok)
+		}
+		if ok && !err.Until.Equal(now.Add(time.Minute)) {
+			t.Fatalf("%d %q: invalid retry time %v", c.status, c.header, err.Until)
+		}
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+	limiter := hostLimiter{}
+
+	// Requests to the same host are spaced by the delay, other hosts aren't
+	// affected.
+	expected := []time.Time{now, now.Add(time.Second), now.Add(2 * time.Second)}
+	for _, want := range expected {
+		start, ok := limiter.reserve("example.com", time.Second, now)
+		if !ok || !start.Equal(want) {
+			t.Fatalf("Expected slot at %v got %v/%v", want, start, ok)
+		}
+	}
+
+	start, ok := limiter.reserve("other.com", time.Second, now)
+	if !ok || !start.Equal(now) {
+		t.Fatalf("Expected immediate slot for other host, got %v/%v", start, ok)
+	}
+
+	// A long Retry-After makes the host unavailable instead of waiting.
+	limiter.deferUntil("other.com", now.Add(time.Hour))
+	start, ok = limiter.reserve("other.com", time.Second, now)
+	if ok || !start.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Expected host to be unavailable until %v, got %v/%v", now.Add(time.Hour), start, ok)
+	}
+}
+
+func TestFetchFeedPolitelyRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	feed := database.Feed{ID: uuid.New(), Url: server.URL + "/feed.xml"}
+
+	db, conn := newFakeDB(t)
+
+	var nextFetchAt, deferredUntil time.Time
+	db.handle("RecordFeedSkipped", func(query string, args []driver.Value) ([]fakeRow, error) {
+		nextFetchAt = args[1].(time.Time)
+		return nil, nil
+	})
+	db.handle("RecordFeedFailure", func(query string, args []driver.Value) ([]fakeRow, error) {
+		t.Fatalf("Expected rate limiting not to be recorded as a failure")
+		return nil, nil
+	})
+	db.handle("DeferHost", func(query string, args []driver.Value) ([]fakeRow, error) {
+		deferredUntil = args[1].(time.Time)
+		return nil, nil
+	})
+	for _, name := range []string{"ExtendFeedClaim", "ReleaseFeedClaim", "CreateFetchAttempt"} {
+		db.handle(name, func(query string, args []driver.Value) ([]fakeRow, error) {
+			return nil, nil
+		})
+	}
+
+	scraper := Scraper{
+		DB:      database.New(conn),
+		Fetcher: &http.Client{},
+	}
+	scraper.fetchFeedPolitely(feedHost(feed), feed, make(chan struct{}, 1))
+
+	if nextFetchAt.IsZero() || !nextFetchAt.Equal(deferredUntil) {
+		t.Fatalf("Expected the feed and its host deferred to the same time, got %v and %v", nextFetchAt, deferredUntil)
+	}
+
+	_, ok := scraper.hosts.reserve(feedHost(feed), time.Second, time.Now().UTC())
+	if ok {
+		t.Fatalf("Expected the host to be deferred")
+	}
+}
+
+func TestFetchAllHonorsSharedHostDeferrals(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	feed := database.Feed{ID: uuid.New(), Url: server.URL + "/feed.xml"}
+	deferredUntil := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+
+	db, conn := newFakeDB(t)
+	db.handle("GetHostDeferrals", func(query string, args []driver.Value) ([]fakeRow, error) {
+		return []fakeRow{{"host": feedHost(feed), "deferred_until": deferredUntil}}, nil
+	})
+
+	var nextFetchAt time.Time
+	db.handle("SetFeedNextFetch", func(query string, args []driver.Value) ([]fakeRow, error) {
+		nextFetchAt = args[1].(time.Time)
+		return nil, nil
+	})
+	db.handle("ReleaseFeedClaim", func(query string, args []driver.Value) ([]fakeRow, error) {
+		return nil, nil
+	})
+
+	scraper := Scraper{
+		DB:      database.New(conn),
+		Fetcher: &http.Client{},
+	}
+	scraper.fetchAll([]database.Feed{feed})
+
+	if requests != 0 {
+		t.Fatalf("Expected the deferred host not to be contacted, got %d requests", requests)
+	}
+	if !nextFetchAt.Equal(deferredUntil) {
+		t.Fatalf("Expected next fetch at %v, got %v", deferredUntil, nextFetchAt)
+	}
+}
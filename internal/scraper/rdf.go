@@ -0,0 +1,47 @@
+package scraper
+
+import "encoding/xml"
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// RDFItem is an RSS 1.0 item. Unlike RSS 2.0, items are siblings of the
+// channel element and carry their timestamp in Dublin Core's dc:date.
+type RDFItem struct {
+	About       string   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description *string  `xml:"description"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Content     *string  `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+type RDFFeed struct {
+	XMLName         xml.Name  `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Title           string    `xml:"channel>title"`
+	UpdatePeriod    string    `xml:"channel>updatePeriod"`
+	UpdateFrequency int       `xml:"channel>updateFrequency"`
+	Items           []RDFItem `xml:"item"`
+}
+
+func (feed RDFFeed) toFeedData() FeedData {
+	feedData := FeedData{
+		Title:          feed.Title,
+		UpdateInterval: updatePeriodInterval(feed.UpdatePeriod, feed.UpdateFrequency),
+	}
+	for _, item := range feed.Items {
+		feedData.Items = append(feedData.Items, FeedItem{
+			GUID:        item.About,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.Date,
+			Content:     item.Content,
+			Author:      item.Creator,
+			Categories:  item.Subjects,
+		})
+	}
+
+	return feedData
+}
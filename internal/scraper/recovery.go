@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Recoveries reported in FeedData.Recoveries and the fetch log.
+const (
+	recoveryLeadingJunk = "stripped leading junk"
+	recoveryLenient     = "lenient parsing"
+)
+
+var (
+	xmlDeclaration = []byte("<?xml")
+	// Matches the start tag of an RSS/RDF item or Atom entry.
+	itemStartTag = regexp.MustCompile(`<(?:item|entry)[\s/>]`)
+	itemEndTag   = regexp.MustCompile(`</(?:item|entry)\s*>`)
+)
+
+// stripLeadingJunk drops anything before the XML declaration, such as a BOM,
+// blank lines or PHP warnings printed by the publisher's CMS. It reports
+// whether anything but whitespace was removed.
+func stripLeadingJunk(data []byte) ([]byte, bool) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	i := bytes.Index(data, xmlDeclaration)
+	if i < 0 {
+		return bytes.TrimLeft(data, " \t\r\n"), false
+	}
+
+	junk := len(bytes.TrimSpace(data[:i])) > 0
+	return data[i:], junk
+}
+
+// recoverXML parses a document the strict decoder rejected with strictErr.
+// Leading junk is stripped and the document decoded leniently; if that still
+// fails, items are salvaged one by one so a single broken item doesn't lose
+// the rest of the feed. The fixes applied are listed in FeedData.Recoveries.
+// If nothing can be recovered strictErr is returned.
+func recoverXML(data []byte, strictErr error) (FeedData, error) {
+	recoveries := []string{}
+
+	feedData, err := FeedData{}, strictErr
+	data, junk := stripLeadingJunk(data)
+	if junk {
+		recoveries = append(recoveries, recoveryLeadingJunk)
+		feedData, err = decodeXML(data, false)
+	}
+
+	if err != nil {
+		feedData, err = decodeXML(data, true)
+		if err == nil {
+			recoveries = append(recoveries, recoveryLenient)
+		}
+	}
+
+	if err != nil {
+		var salvaged, total int
+		feedData, salvaged, total, err = salvageItems(data)
+		if err != nil {
+			return FeedData{}, strictErr
+		}
+		recoveries = append(recoveries, recoveryLenient, fmt.Sprintf("salvaged %d of %d items", salvaged, total))
+	}
+
+	feedData.Recoveries = recoveries
+	return feedData, nil
+}
+
+// salvageItems decodes the feed's header and each of its items separately.
+// Each item is decoded inside a copy of the header so namespace prefixes
+// declared on the root element still resolve. It returns the feed with the
+// items that could be decoded, and how many there were in total.
+func salvageItems(data []byte) (FeedData, int, int, error) {
+	starts := itemStartTag.FindAllIndex(data, -1)
+	if len(starts) == 0 {
+		return FeedData{}, 0, 0, errors.New("no items to salvage")
+	}
+
+	header := data[:starts[0][0]]
+	closers, err := closingTags(header)
+	if err != nil {
+		return FeedData{}, 0, 0, err
+	}
+
+	feedData, err := decodeXML(concat(header, closers), true)
+	if err != nil {
+		return FeedData{}, 0, 0, err
+	}
+
+	for i, start := range starts {
+		end := len(data)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+
+		item := data[start[0]:end]
+		if loc := itemEndTag.FindIndex(item); loc != nil {
+			item = item[:loc[1]]
+		}
+
+		itemData, err := decodeXML(concat(header, item, closers), true)
+		if err != nil || len(itemData.Items) != 1 {
+			continue
+		}
+
+		feedData.Items = append(feedData.Items, itemData.Items[0])
+	}
+
+	return feedData, len(feedData.Items), len(starts), nil
+}
+
+// closingTags returns the end tags for the elements left open at the end of
+// a document fragment, innermost first.
+func closingTags(fragment []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(fragment))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	open := []xml.Name{}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			open = append(open, token.Name)
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	closers := []byte{}
+	for i := len(open) - 1; i >= 0; i-- {
+		name := open[i].Local
+		if open[i].Space != "" {
+			name = open[i].Space + ":" + name
+		}
+		closers = append(closers, "</"+name+">"...)
+	}
+
+	return closers, nil
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
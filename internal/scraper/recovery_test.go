@@ -0,0 +1,122 @@
+package scraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseXMLRecoveries(t *testing.T) {
+	cases := []struct {
+		name       string
+		xml        string
+		titles     []string
+		links      []string
+		guids      []string
+		recoveries []string
+	}{
+		{
+			name:       "well-formed",
+			xml:        `<rss><channel><title>Feed</title><item><title>One</title></item></channel></rss>`,
+			titles:     []string{"One"},
+			recoveries: nil,
+		},
+		{
+			name:       "unescaped ampersand and html entity",
+			xml:        `<rss><channel><title>Feed</title><item><title>Fish & Chips&nbsp;Night</title></item></channel></rss>`,
+			titles:     []string{"Fish & Chips\u00a0Night"},
+			recoveries: []string{"lenient parsing"},
+		},
+		{
+			name: "html entity with links and guids",
+			xml: `<rss><channel><title>Feed</title><link>https://example.com/</link><description>News&nbsp;feed</description>
+				<item><title>One</title><link>https://example.com/1</link><guid>tag:example.com,2024:1</guid></item>
+				<item><title>Two</title><link>https://example.com/2</link><guid>tag:example.com,2024:2</guid></item>
+			</channel></rss>`,
+			titles:     []string{"One", "Two"},
+			links:      []string{"https://example.com/1", "https://example.com/2"},
+			guids:      []string{"tag:example.com,2024:1", "tag:example.com,2024:2"},
+			recoveries: []string{"lenient parsing"},
+		},
+		{
+			name:       "junk before declaration",
+			xml:        "<b>Warning</b>: session_start() failed\n<?xml version=\"1.0\"?><rss><channel><title>Feed</title><item><title>One</title></item></channel></rss>",
+			titles:     []string{"One"},
+			recoveries: []string{"stripped leading junk"},
+		},
+		{
+			name: "broken item",
+			xml: `<rss xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>Feed</title>
+				<item><title>One</title><link>https://example.com/1</link><guid>1</guid><dc:creator>Jane</dc:creator></item>
+				<item><title>Two</title><description><![CDATA[unterminated</description></item>
+				<item><title>Three</title><link>https://example.com/3</link><guid>3</guid></item>
+			</channel></rss>`,
+			titles:     []string{"One", "Three"},
+			links:      []string{"https://example.com/1", "https://example.com/3"},
+			guids:      []string{"1", "3"},
+			recoveries: []string{"lenient parsing", "salvaged 2 of 3 items"},
+		},
+		{
+			name: "truncated feed",
+			xml: `<rss><channel><title>Feed</title>
+				<item><title>One</title></item>
+				<item><title>Two</tit`,
+			titles:     []string{"One"},
+			recoveries: []string{"lenient parsing", "salvaged 1 of 2 items"},
+		},
+	}
+
+	for _, c := range cases {
+		feedData, err := parseXML(strings.NewReader(c.xml))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if feedData.Title != "Feed" {
+			t.Fatalf("%s: invalid title: got '%s'", c.name, feedData.Title)
+		}
+
+		titles, links, guids := []string{}, []string{}, []string{}
+		for _, item := range feedData.Items {
+			titles = append(titles, item.Title)
+			links = append(links, item.Link)
+			guids = append(guids, item.GUID)
+		}
+		if !reflect.DeepEqual(titles, c.titles) {
+			t.Fatalf("%s: expected items %v got %v", c.name, c.titles, titles)
+		}
+		if c.links != nil && !reflect.DeepEqual(links, c.links) {
+			t.Fatalf("%s: expected links %v got %v", c.name, c.links, links)
+		}
+		if c.guids != nil && !reflect.DeepEqual(guids, c.guids) {
+			t.Fatalf("%s: expected GUIDs %v got %v", c.name, c.guids, guids)
+		}
+
+		if !reflect.DeepEqual(feedData.Recoveries, c.recoveries) {
+			t.Fatalf("%s: expected recoveries %v got %v", c.name, c.recoveries, feedData.Recoveries)
+		}
+	}
+}
+
+func TestParseXMLSalvageKeepsNamespaces(t *testing.T) {
+	xml := `<rss xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>Feed</title>
+		<item><title>One</title><dc:creator>Jane</dc:creator></item>
+		<item><title>Two</title><description><![CDATA[unterminated</description></item>
+	</channel></rss>`
+
+	feedData, err := parseXML(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(feedData.Items) != 1 || feedData.Items[0].Author != "Jane" {
+		t.Fatalf("Invalid salvaged items: got %+v", feedData.Items)
+	}
+}
+
+func TestParseXMLUnrecoverable(t *testing.T) {
+	_, err := parseXML(strings.NewReader(`<rss><channel><title>Feed`))
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+}
@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/lib/pq"
+)
+
+// permanentRedirect returns where a response's feed has permanently moved
+// to. Only the leading run of 301/308 hops counts: once a temporary redirect
+// is seen, later hops are ignored. It works with any client following
+// redirects, as each request made to follow one points to the response that
+// caused it.
+func permanentRedirect(resp *http.Response) string {
+	hops := []*http.Request{}
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		hops = append(hops, req)
+	}
+
+	permanentURL := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		switch hops[i].Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+			permanentURL = hops[i].URL.String()
+		default:
+			return permanentURL
+		}
+	}
+
+	return permanentURL
+}
+
+// ErrFeedMerged is returned when a feed moved to the URL of another
+// registered feed and was merged into it.
+var ErrFeedMerged = errors.New("feed merged into another feed")
+
+// updateFeedUrl points a feed at the location it permanently moved to. If
+// another feed is already registered at that URL, the two are merged into
+// the existing one and ErrFeedMerged is returned. Other errors are only
+// logged, as the feed can still be used at its current URL.
+func (s *Scraper) updateFeedUrl(feed database.Feed, newUrl string) error {
+	ctx := context.Background()
+
+	_, err := s.DB.UpdateFeedUrl(ctx, database.UpdateFeedUrlParams{
+		ID:  feed.ID,
+		Url: newUrl,
+	})
+	if err == nil {
+		log.Printf("Feed %s permanently moved to %s\n", feed.Url, newUrl)
+		return nil
+	}
+
+	if err, ok := err.(*pq.Error); !ok || err.Code.Name() != "unique_violation" {
+		log.Printf("Error updating URL of %s: %v\n", feed.Url, err)
+		return nil
+	}
+
+	target, err := s.DB.GetFeedByUrl(ctx, newUrl)
+	if err != nil {
+		log.Printf("Error finding feed registered at %s: %v\n", newUrl, err)
+		return nil
+	}
+
+	err = s.mergeFeeds(ctx, feed, target)
+	if err != nil {
+		log.Printf("Error merging %s into %s: %v\n", feed.Url, target.Url, err)
+		return nil
+	}
+
+	log.Printf("Feed %s permanently moved to already registered %s, merged\n", feed.Url, newUrl)
+	return ErrFeedMerged
+}
+
+// mergeFeeds moves the follows, posts and fetch history of a feed to the
+// feed it moved to, then deletes it, all in one transaction. Posts the
+// target already has are dropped along with the feed.
+func (s *Scraper) mergeFeeds(ctx context.Context, from database.Feed, to database.Feed) error {
+	return s.inTx(ctx, func(q *database.Queries) error {
+		err := q.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{
+			ToFeedID:   to.ID,
+			FromFeedID: from.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.MovePosts(ctx, database.MovePostsParams{
+			ToFeedID:   to.ID,
+			FromFeedID: from.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.MoveFetchAttempts(ctx, database.MoveFetchAttemptsParams{
+			ToFeedID:   to.ID,
+			FromFeedID: from.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		duplicates, err := q.CountFeedPosts(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		if duplicates > 0 {
+			log.Printf("Dropping %d posts of %s already stored for %s\n", duplicates, from.Url, to.Url)
+		}
+
+		return q.DeleteFeed(ctx, from.ID)
+	})
+}
@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPermanentRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/new", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("<rss></rss>"))
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/old", http.StatusFound)
+	})
+	mux.HandleFunc("/mixed", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, "/temporary", http.StatusMovedPermanently)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{"/new", ""},
+		{"/old", server.URL + "/new"},
+		{"/temporary", ""},
+		{"/mixed", server.URL + "/temporary"},
+	}
+
+	fetchers := map[string]Fetcher{
+		"http.Client": &http.Client{},
+		"HTTPFetcher": defaultFetcher,
+	}
+
+	for name, fetcher := range fetchers {
+		for _, c := range cases {
+			req, err := http.NewRequest("GET", server.URL+c.path, nil)
+			if err != nil {
+				t.Fatalf("Failed: %v", err)
+			}
+
+			resp, err := fetcher.Do(req)
+			if err != nil {
+				t.Fatalf("Failed: %v", err)
+			}
+			resp.Body.Close()
+
+			permanentURL := permanentRedirect(resp)
+			if permanentURL != c.expected {
+				t.Fatalf("%s: invalid permanent URL for %s: expected '%s' got '%s'", name, c.path, c.expected, permanentURL)
+			}
+		}
+	}
+}
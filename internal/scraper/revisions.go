@@ -0,0 +1,106 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+// updateEditedPost handles an item that is already stored for its feed. If
+// the publisher changed it since, the stored post is updated in place and
+// its previous version is kept as a revision.
+func (s *Scraper) updateEditedPost(params database.CreatePostParams, item FeedItem) {
+	ctx := context.Background()
+
+	post, err := s.DB.GetPostByGuid(ctx, database.GetPostByGuidParams{
+		FeedID: params.FeedID,
+		Guid:   params.Guid,
+	})
+	if err != nil {
+		log.Println("Failed to get existing Post from DB:", params.Title)
+		return
+	}
+
+	if post.ContentHash == params.ContentHash {
+		log.Println("Post already in DB for this feed, skipping.")
+		return
+	}
+
+	// A hash from an older scheme that still matches the item only changed
+	// with the scheme, so the post is updated without recording a revision.
+	rehashed := post.ContentHashVersion < contentHashVersion && slices.Contains(legacyContentHashes(item), post.ContentHash)
+
+	// Keep the first-seen date of posts whose date can't be parsed instead of
+	// moving them to the time of the edit.
+	publishedAt := params.PublishedAt
+	if _, err := parsePubDate(item.PubDate); err != nil {
+		publishedAt = post.PublishedAt
+	}
+
+	// Saving the revision and the edit together keeps a failed update from
+	// recording the same revision again on every later fetch.
+	err = s.inTx(ctx, func(q *database.Queries) error {
+		if !rehashed {
+			_, err := q.CreatePostRevision(ctx, database.CreatePostRevisionParams{
+				ID:          uuid.New(),
+				CreatedAt:   time.Now().UTC(),
+				PostID:      post.ID,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+				ContentHash: post.ContentHash,
+				Content:     post.Content,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save revision: %v", err)
+			}
+		}
+
+		_, err := q.UpdatePostContent(ctx, database.UpdatePostContentParams{
+			ID:                 post.ID,
+			Title:              params.Title,
+			Url:                params.Url,
+			Description:        params.Description,
+			PublishedAt:        publishedAt,
+			ContentHash:        params.ContentHash,
+			Content:            params.Content,
+			Author:             params.Author,
+			CommentsUrl:        params.CommentsUrl,
+			DurationSeconds:    params.DurationSeconds,
+			Episode:            params.Episode,
+			Season:             params.Season,
+			ImageUrl:           params.ImageUrl,
+			Explicit:           params.Explicit,
+			DescriptionText:    params.DescriptionText,
+			ContentText:        params.ContentText,
+			ContentHashVersion: params.ContentHashVersion,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update post: %v", err)
+		}
+
+		err = q.DeletePostCategories(ctx, post.ID)
+		if err != nil {
+			return fmt.Errorf("failed to clear categories: %v", err)
+		}
+
+		savePostExtras(ctx, q, post.ID, item)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to update edited Post %s: %v\n", params.Title, err)
+		return
+	}
+
+	if rehashed {
+		log.Println("Updated hash of Post in DB:", params.Title)
+		return
+	}
+	log.Println("Updated edited Post in DB:", params.Title)
+}
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+func storedPost(feedID uuid.UUID, guid string, hash string, hashVersion int64) fakeRow {
+	createdAt := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+
+	return fakeRow{
+		"id":                   uuid.New().String(),
+		"created_at":           createdAt,
+		"updated_at":           createdAt,
+		"title":                "Entry",
+		"url":                  "https://example.com/1",
+		"published_at":         createdAt,
+		"feed_id":              feedID.String(),
+		"guid":                 guid,
+		"content_hash":         hash,
+		"content_hash_version": hashVersion,
+	}
+}
+
+func TestUpdateEditedPost(t *testing.T) {
+	cases := []struct {
+		name      string
+		updateErr error
+		committed bool
+	}{
+		{"edit", nil, true},
+		{"failed update", errors.New("connection lost"), false},
+	}
+
+	for _, c := range cases {
+		db, conn := newFakeDB(t)
+		posts := handlePosts(db)
+
+		feedID := uuid.New()
+		post := storedPost(feedID, "tag:example.com,2024:1", "old hash", contentHashVersion)
+		posts.rows = append(posts.rows, post)
+		categories := []string{"Old"}
+
+		db.handle("CreatePostRevision", func(query string, args []driver.Value) ([]fakeRow, error) {
+			return []fakeRow{insertedRow(query, args)}, nil
+		})
+		db.handle("UpdatePostContent", func(query string, args []driver.Value) ([]fakeRow, error) {
+			if c.updateErr != nil {
+				return nil, c.updateErr
+			}
+			return []fakeRow{post}, nil
+		})
+		db.handle("DeletePostCategories", func(query string, args []driver.Value) ([]fakeRow, error) {
+			categories = nil
+			return nil, nil
+		})
+		db.handle("CreatePostCategory", func(query string, args []driver.Value) ([]fakeRow, error) {
+			categories = append(categories, args[1].(string))
+			return nil, nil
+		})
+
+		scraper := Scraper{DB: database.New(conn), Conn: conn}
+		item := FeedItem{
+			Title:      "Edited entry",
+			Link:       "https://example.com/1",
+			GUID:       "tag:example.com,2024:1",
+			Categories: []string{"Go"},
+		}
+		scraper.updateEditedPost(database.CreatePostParams{
+			FeedID:      feedID,
+			Title:       item.Title,
+			Url:         item.Link,
+			Guid:        item.GUID,
+			ContentHash: contentHash(item),
+		}, item)
+
+		committed := db.commits == 1 && db.rollbacks == 0
+		rolledBack := db.commits == 0 && db.rollbacks == 1
+		if (c.committed && !committed) || (!c.committed && !rolledBack) {
+			t.Fatalf("%s: expected committed %v, got %d commits and %d rollbacks", c.name, c.committed, db.commits, db.rollbacks)
+		}
+
+		if c.committed && (len(categories) != 1 || categories[0] != "Go") {
+			t.Fatalf("%s: expected categories to be replaced, got %v", c.name, categories)
+		}
+	}
+}
+
+func TestUpdateEditedPostLegacyHash(t *testing.T) {
+	db, conn := newFakeDB(t)
+	posts := handlePosts(db)
+
+	description := "Entry description"
+	content := "<p>Full content</p>"
+	item := FeedItem{
+		Title:       "Entry",
+		Link:        "https://example.com/1",
+		GUID:        "tag:example.com,2024:1",
+		Description: &description,
+		Content:     &content,
+	}
+
+	// Hashed before full content was captured, and unchanged since.
+	feedID := uuid.New()
+	post := storedPost(feedID, item.GUID, hashString(item.Title+"\n"+item.Link+"\n"+description), 1)
+	posts.rows = append(posts.rows, post)
+
+	updated := false
+	db.handle("UpdatePostContent", func(query string, args []driver.Value) ([]fakeRow, error) {
+		updated = true
+		return []fakeRow{post}, nil
+	})
+	db.handle("DeletePostCategories", func(query string, args []driver.Value) ([]fakeRow, error) {
+		return nil, nil
+	})
+
+	scraper := Scraper{DB: database.New(conn), Conn: conn}
+	scraper.updateEditedPost(database.CreatePostParams{
+		FeedID:             feedID,
+		Title:              item.Title,
+		Url:                item.Link,
+		Guid:               item.GUID,
+		ContentHash:        contentHash(item),
+		ContentHashVersion: contentHashVersion,
+	}, item)
+
+	// No CreatePostRevision handler is registered, so recording a revision
+	// would roll the transaction back.
+	if !updated || db.commits != 1 {
+		t.Fatalf("Expected post to be rehashed without a revision, got calls %v", db.calls)
+	}
+}
@@ -0,0 +1,283 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	robotsCacheInterval = 24 * time.Hour
+	// robots.txt files that couldn't be fetched are retried sooner.
+	robotsErrorCacheInterval = time.Hour
+	maxRobotsSize            = 500 << 10 // 500 KiB, as in RFC 9309
+)
+
+// RobotsDisallowedError is returned for URLs the host's robots.txt doesn't
+// allow the scraper's User-Agent to fetch.
+type RobotsDisallowedError struct {
+	Url string
+	// When the cached robots.txt expires and the URL is worth retrying.
+	Until time.Time
+}
+
+func (e RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("disallowed by robots.txt: %s", e.Url)
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsTxt holds the groups of a parsed robots.txt file.
+type robotsTxt struct {
+	groups []robotsGroup
+}
+
+// parseRobotsTxt parses a robots.txt file as described by RFC 9309. Lines it
+// doesn't understand are ignored.
+func parseRobotsTxt(data []byte) robotsTxt {
+	robots := robotsTxt{}
+	var group *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share the rules that follow them.
+			if group == nil || inRules {
+				robots.groups = append(robots.groups, robotsGroup{})
+				group = &robots.groups[len(robots.groups)-1]
+				inRules = false
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+
+		case "allow", "disallow":
+			if group == nil {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{
+				allow:   key == "allow",
+				pattern: value,
+			})
+		}
+	}
+
+	return robots
+}
+
+// allowed reports whether userAgent may fetch path, which includes the query.
+// Rules come from the groups naming the User-Agent's product token, or from
+// the "*" groups when none do. The longest matching rule wins, and allow
+// wins ties.
+func (r robotsTxt) allowed(userAgent string, path string) bool {
+	product, _, _ := strings.Cut(userAgent, "/")
+	product = strings.ToLower(strings.TrimSpace(product))
+
+	rules := []robotsRule{}
+	wildcard := []robotsRule{}
+	for _, group := range r.groups {
+		for _, agent := range group.agents {
+			if agent == product {
+				rules = append(rules, group.rules...)
+			} else if agent == "*" {
+				wildcard = append(wildcard, group.rules...)
+			}
+		}
+	}
+	if len(rules) == 0 {
+		rules = wildcard
+	}
+
+	matched := robotsRule{allow: true}
+	matchedLength := -1
+	for _, rule := range rules {
+		if !robotsPatternMatches(rule.pattern, path) {
+			continue
+		}
+
+		length := len(rule.pattern)
+		if length > matchedLength || (length == matchedLength && rule.allow) {
+			matched = rule
+			matchedLength = length
+		}
+	}
+
+	return matched.allow
+}
+
+// robotsPatternMatches matches a path against a rule, where "*" matches any
+// sequence of characters and a trailing "$" anchors the end of the path.
+func robotsPatternMatches(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
+
+type robotsEntry struct {
+	robots    robotsTxt
+	expiresAt time.Time
+}
+
+// robotsCache keeps each host's parsed robots.txt.
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+func (c *robotsCache) get(origin string, now time.Time) (robotsEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[origin]
+	if !ok || now.After(entry.expiresAt) {
+		return robotsEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (c *robotsCache) set(origin string, robots robotsTxt, expiresAt time.Time) robotsEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]robotsEntry{}
+	}
+	c.entries[origin] = robotsEntry{
+		robots:    robots,
+		expiresAt: expiresAt,
+	}
+
+	return c.entries[origin]
+}
+
+// disallowAll stands in for robots.txt files that couldn't be fetched, which
+// RFC 9309 says to treat as disallowing everything.
+var disallowAll = robotsTxt{
+	groups: []robotsGroup{{
+		agents: []string{"*"},
+		rules:  []robotsRule{{allow: false, pattern: "/"}},
+	}},
+}
+
+// robotsFor returns the cached robots.txt of the URL's origin, fetching it
+// when it isn't cached. A missing robots.txt (4xx) allows everything, while
+// server and network errors disallow everything until it is retried.
+func (s *Scraper) robotsFor(target *url.URL) robotsEntry {
+	origin := target.Scheme + "://" + target.Host
+	now := time.Now().UTC()
+
+	if entry, ok := s.robots.get(origin, now); ok {
+		return entry
+	}
+
+	robots, err := s.fetchRobotsTxt(origin)
+	if err != nil {
+		log.Printf("Error fetching robots.txt for %s: %v\n", origin, err)
+		return s.robots.set(origin, disallowAll, now.Add(robotsErrorCacheInterval))
+	}
+
+	return s.robots.set(origin, robots, now.Add(robotsCacheInterval))
+}
+
+func (s *Scraper) fetchRobotsTxt(origin string) (robotsTxt, error) {
+	req, err := http.NewRequest("GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return robotsTxt{}, err
+	}
+
+	resp, err := s.fetcher().Do(req)
+	if err != nil {
+		return robotsTxt{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return robotsTxt{}, nil
+	}
+	if resp.StatusCode >= 500 {
+		return robotsTxt{}, fmt.Errorf("status %v", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return robotsTxt{}, err
+	}
+
+	return parseRobotsTxt(body), nil
+}
+
+// checkRobots returns a RobotsDisallowedError if robots.txt compliance is
+// enabled and the URL's host disallows fetching it.
+func (s *Scraper) checkRobots(target *url.URL) error {
+	if !s.RespectRobots {
+		return nil
+	}
+
+	entry := s.robotsFor(target)
+	if !entry.robots.allowed(s.userAgent(), target.RequestURI()) {
+		return RobotsDisallowedError{
+			Url:   target.String(),
+			Until: entry.expiresAt,
+		}
+	}
+
+	return nil
+}
+
+// userAgent returns the User-Agent the fetcher sends, which robots.txt rules
+// are evaluated against.
+func (s *Scraper) userAgent() string {
+	if fetcher, ok := s.fetcher().(interface{ UserAgent() string }); ok {
+		return fetcher.UserAgent()
+	}
+
+	return DefaultUserAgent
+}
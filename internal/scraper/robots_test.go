@@ -0,0 +1,168 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRobotsTxtAllowed(t *testing.T) {
+	robots := parseRobotsTxt([]byte(`
+# Comments and unknown lines are ignored
+Sitemap: https://example.com/sitemap.xml
+
+User-agent: *
+Disallow: /private/
+Disallow: /*.json$
+Allow: /private/feed.xml
+
+User-agent: BadBot
+User-agent: gorss
+Disallow: /feeds/
+Allow: /feeds/public
+Disallow: /*?session=
+
+User-agent: other
+Disallow: /
+`))
+
+	cases := []struct {
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{"SomeBot/2.0", "/index.xml", true},
+		{"SomeBot/2.0", "/private/data.xml", false},
+		{"SomeBot/2.0", "/private/feed.xml", true},
+		{"SomeBot/2.0", "/feed.json", false},
+		{"SomeBot/2.0", "/feed.json?page=2", true},
+		// gorss has its own group, so the "*" rules don't apply to it.
+		{DefaultUserAgent, "/private/data.xml", true},
+		{DefaultUserAgent, "/feeds/all.xml", false},
+		{DefaultUserAgent, "/feeds/public.xml", true},
+		{DefaultUserAgent, "/index.xml?session=1", false},
+		{"Other", "/index.xml", false},
+	}
+
+	for _, c := range cases {
+		allowed := robots.allowed(c.userAgent, c.path)
+		if allowed != c.expected {
+			t.Fatalf("%s %s: expected %v got %v", c.userAgent, c.path, c.expected, allowed)
+		}
+	}
+}
+
+func TestCheckRobots(t *testing.T) {
+	robotsRequests := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, req *http.Request) {
+		robotsRequests++
+		w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+
+	scraper := Scraper{RespectRobots: true}
+	now := time.Now().UTC()
+
+	cases := []struct {
+		url        string
+		disallowed bool
+		retryAfter time.Duration
+	}{
+		{server.URL + "/feed.xml", false, 0},
+		{server.URL + "/private/feed.xml", true, robotsCacheInterval},
+		{unavailable.URL + "/feed.xml", true, robotsErrorCacheInterval},
+		{missing.URL + "/feed.xml", false, 0},
+	}
+
+	for _, c := range cases {
+		req, err := http.NewRequest("GET", c.url, nil)
+		if err != nil {
+			t.Fatalf("Failed: %v", err)
+		}
+
+		// Every outbound request goes through the check, not just the
+		// scraper's feed fetches.
+		resp, err := scraper.do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		var disallowed RobotsDisallowedError
+		if errors.As(err, &disallowed) != c.disallowed {
+			t.Fatalf("%s: expected disallowed %v got %v", c.url, c.disallowed, err)
+		}
+		if c.disallowed && disallowed.Until.Sub(now).Round(time.Minute) != c.retryAfter {
+			t.Fatalf("%s: expected a retry in %v, got %v", c.url, c.retryAfter, disallowed.Until)
+		}
+	}
+
+	if robotsRequests != 1 {
+		t.Fatalf("Expected robots.txt to be fetched once, got %d", robotsRequests)
+	}
+
+	scraper.RespectRobots = false
+	private, _ := url.Parse(server.URL + "/private/feed.xml")
+	err := scraper.checkRobots(private)
+	if err != nil {
+		t.Fatalf("Expected robots.txt to be ignored when disabled, got %v", err)
+	}
+}
+
+func TestRobotsRedirects(t *testing.T) {
+	fetched := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+	})
+	mux.Handle("/moved.xml", http.RedirectHandler("/private/feed.xml", http.StatusMovedPermanently))
+	mux.HandleFunc("/private/feed.xml", func(w http.ResponseWriter, req *http.Request) {
+		fetched++
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetchers := map[string]Fetcher{
+		"http.Client": &http.Client{CheckRedirect: CheckRedirect},
+		"HTTPFetcher": defaultFetcher,
+	}
+
+	for name, fetcher := range fetchers {
+		scraper := Scraper{RespectRobots: true, Fetcher: fetcher}
+
+		req, err := http.NewRequest("GET", server.URL+"/moved.xml", nil)
+		if err != nil {
+			t.Fatalf("Failed: %v", err)
+		}
+
+		resp, err := scraper.do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		var disallowed RobotsDisallowedError
+		if !errors.As(err, &disallowed) || disallowed.Url != server.URL+"/private/feed.xml" {
+			t.Fatalf("%s: expected the redirect to be disallowed, got %v", name, err)
+		}
+	}
+
+	if fetched != 0 {
+		t.Fatalf("Expected the disallowed redirect target not to be fetched, got %d requests", fetched)
+	}
+}
@@ -0,0 +1,384 @@
+package scraper
+
+import (
+	"bytes"
+	"database/sql"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements lists the elements kept by sanitizeHTML and the attributes
+// allowed on each. Other elements are unwrapped, keeping their children.
+var allowedElements = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Abbr:       {"title"},
+	atom.B:          nil,
+	atom.Blockquote: {"cite"},
+	atom.Br:         nil,
+	atom.Caption:    nil,
+	atom.Cite:       nil,
+	atom.Code:       nil,
+	atom.Dd:         nil,
+	atom.Del:        nil,
+	atom.Div:        nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Em:         nil,
+	atom.Figcaption: nil,
+	atom.Figure:     nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Img:        {"src", "alt", "title", "width", "height"},
+	atom.Ins:        nil,
+	atom.Li:         nil,
+	atom.Ol:         nil,
+	atom.P:          nil,
+	atom.Pre:        nil,
+	atom.Q:          {"cite"},
+	atom.S:          nil,
+	atom.Small:      nil,
+	atom.Span:       nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Table:      nil,
+	atom.Tbody:      nil,
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Tfoot:      nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Thead:      nil,
+	atom.Tr:         nil,
+	atom.U:          nil,
+	atom.Ul:         nil,
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Form:     true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// blockElements start a new line in the plain-text rendition.
+var blockElements = map[atom.Atom]bool{
+	atom.Blockquote: true,
+	atom.Br:         true,
+	atom.Dd:         true,
+	atom.Div:        true,
+	atom.Dt:         true,
+	atom.Figcaption: true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Hr:         true,
+	atom.Li:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Tr:         true,
+}
+
+var urlAttributes = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// trackingParams are query parameters stripped from links, in addition to
+// any starting with "utm_".
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"igshid":  true,
+	"yclid":   true,
+	"msclkid": true,
+}
+
+// sanitizeHTML returns a copy of an HTML fragment keeping only the allowlisted
+// elements and attributes. Relative URLs are resolved against base, links
+// with other schemes (e.g. javascript:) are removed, tracking parameters are
+// stripped and 1x1 tracking pixels are dropped.
+func sanitizeHTML(fragment string, base string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		baseUrl = nil
+	}
+
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		renderSanitized(&buf, node, baseUrl)
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+func renderSanitized(buf *bytes.Buffer, node *html.Node, base *url.URL) {
+	switch node.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(node.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if droppedElements[node.DataAtom] {
+		return
+	}
+
+	allowed, ok := allowedElements[node.DataAtom]
+	if !ok {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			renderSanitized(buf, child, base)
+		}
+		return
+	}
+
+	attrs := sanitizeAttributes(node.Attr, allowed, base)
+	if node.DataAtom == atom.Img && (!hasAttribute(attrs, "src") || isTrackingPixel(attrs)) {
+		return
+	}
+	if node.DataAtom == atom.A && hasAttribute(attrs, "href") {
+		attrs = append(attrs, html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"})
+	}
+
+	buf.WriteString("<" + node.Data)
+	for _, attr := range attrs {
+		buf.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	buf.WriteString(">")
+
+	if node.DataAtom == atom.Br || node.DataAtom == atom.Hr || node.DataAtom == atom.Img {
+		return
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		renderSanitized(buf, child, base)
+	}
+	buf.WriteString("</" + node.Data + ">")
+}
+
+func sanitizeAttributes(attrs []html.Attribute, allowed []string, base *url.URL) []html.Attribute {
+	result := []html.Attribute{}
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !containsString(allowed, attr.Key) {
+			continue
+		}
+
+		if urlAttributes[attr.Key] {
+			cleaned, ok := cleanURL(attr.Val, base)
+			if !ok {
+				continue
+			}
+			attr.Val = cleaned
+		}
+
+		result = append(result, attr)
+	}
+
+	return result
+}
+
+// cleanURL resolves a URL found in content against base and strips tracking
+// parameters. It reports false for URLs that must not be kept.
+func cleanURL(raw string, base *url.URL) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", false
+	}
+
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+
+	if !allowedSchemes[strings.ToLower(parsed.Scheme)] {
+		return "", false
+	}
+
+	return stripTrackingParams(parsed).String(), true
+}
+
+// stripTrackingParams removes utm_* and other known tracking parameters from
+// a URL's query.
+func stripTrackingParams(u *url.URL) *url.URL {
+	if u.RawQuery == "" {
+		return u
+	}
+
+	query := u.Query()
+	changed := false
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+			changed = true
+		}
+	}
+
+	if changed {
+		cleaned := *u
+		cleaned.RawQuery = query.Encode()
+		return &cleaned
+	}
+
+	return u
+}
+
+// cleanLink strips tracking parameters from an item's link, leaving links it
+// can't parse unchanged.
+func cleanLink(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme == "" {
+		return link
+	}
+
+	return stripTrackingParams(parsed).String()
+}
+
+func isTrackingPixel(attrs []html.Attribute) bool {
+	for _, attr := range attrs {
+		if (attr.Key == "width" || attr.Key == "height") && (attr.Val == "0" || attr.Val == "1") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAttribute(attrs []html.Attribute, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// htmlToText returns the text of an HTML fragment, with block elements on
+// their own lines and other whitespace collapsed.
+func htmlToText(fragment string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return fragment
+	}
+
+	var buf strings.Builder
+	for _, node := range nodes {
+		renderText(&buf, node)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderText(buf *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		buf.WriteString(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if droppedElements[node.DataAtom] {
+		return
+	}
+
+	block := blockElements[node.DataAtom]
+	if block {
+		buf.WriteString("\n")
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		renderText(buf, child)
+	}
+	if block {
+		buf.WriteString("\n")
+	} else if node.DataAtom == atom.Td || node.DataAtom == atom.Th {
+		buf.WriteString(" ")
+	}
+}
+
+// sanitizedRenditions returns the sanitized HTML and plain-text renditions of
+// an item's description or content, resolving URLs against the item's link.
+func sanitizedRenditions(fragment *string, link string) (sql.NullString, sql.NullString) {
+	if fragment == nil {
+		return sql.NullString{}, sql.NullString{}
+	}
+
+	sanitized := sanitizeHTML(*fragment, link)
+	return sql.NullString{String: sanitized, Valid: true},
+		sql.NullString{String: htmlToText(sanitized), Valid: true}
+}
+
+// SanitizedRenditions sanitizes a stored fragment and renders it as plain
+// text, for content stored before it was sanitized at ingest. Sanitizing
+// already sanitized HTML leaves it unchanged.
+func SanitizedRenditions(fragment sql.NullString, link string) (sql.NullString, sql.NullString) {
+	if !fragment.Valid {
+		return sql.NullString{}, sql.NullString{}
+	}
+
+	return sanitizedRenditions(&fragment.String, link)
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	base := "https://example.com/blog/post-1"
+
+	cases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "plain text",
+			html:     "Fish & chips < 5",
+			expected: "Fish &amp; chips &lt; 5",
+		},
+		{
+			name:     "script and style",
+			html:     `<p>Hello</p><script>alert(1)</script><style>p{}</style>`,
+			expected: `<p>Hello</p>`,
+		},
+		{
+			name:     "event handlers and unknown elements",
+			html:     `<p onclick="steal()" class="x"><font color="red">Hi</font></p>`,
+			expected: `<p>Hi</p>`,
+		},
+		{
+			name:     "javascript link",
+			html:     `<a href="javascript:alert(1)">Click</a>`,
+			expected: `<a>Click</a>`,
+		},
+		{
+			name:     "relative urls",
+			html:     `<a href="/about">About</a><img src="img/cat.png" alt="Cat">`,
+			expected: `<a href="https://example.com/about" rel="nofollow noopener noreferrer">About</a><img src="https://example.com/blog/img/cat.png" alt="Cat">`,
+		},
+		{
+			name:     "tracking parameters",
+			html:     `<a href="https://other.com/page?id=3&utm_source=rss&utm_medium=feed&fbclid=abc">Page</a>`,
+			expected: `<a href="https://other.com/page?id=3" rel="nofollow noopener noreferrer">Page</a>`,
+		},
+		{
+			name:     "tracking pixel",
+			html:     `<p>Text<img src="https://tracker.com/p.gif" width="1" height="1"></p>`,
+			expected: `<p>Text</p>`,
+		},
+	}
+
+	for _, c := range cases {
+		result := sanitizeHTML(c.html, base)
+		if result != c.expected {
+			t.Fatalf("%s: expected '%s' got '%s'", c.name, c.expected, result)
+		}
+
+		// Stored content may be sanitized again on output.
+		again := sanitizeHTML(result, base)
+		if again != result {
+			t.Fatalf("%s: sanitizing again changed '%s' to '%s'", c.name, result, again)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	text := htmlToText(`<h1>Title</h1><p>First   paragraph with <a href="https://example.com">a link</a>.</p><ul><li>One</li><li>Two</li></ul>Fish &amp; chips`)
+
+	expected := "Title\nFirst paragraph with a link.\nOne\nTwo\nFish & chips"
+	if text != expected {
+		t.Fatalf("Invalid text: expected %q got %q", expected, text)
+	}
+}
+
+func TestCleanLink(t *testing.T) {
+	link := cleanLink("https://example.com/post?utm_campaign=rss&p=1")
+	if link != "https://example.com/post?p=1" {
+		t.Fatalf("Invalid link: got '%s'", link)
+	}
+}
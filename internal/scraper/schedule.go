@@ -0,0 +1,157 @@
+package scraper
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+)
+
+const (
+	minFetchInterval     = 5 * time.Minute
+	maxFetchInterval     = 24 * time.Hour
+	defaultFetchInterval = time.Hour
+
+	// Number of most recent items used to estimate how often a feed posts.
+	scheduleSampleSize = 10
+)
+
+// previousInterval is the polling interval chosen at the last fetch, reused
+// when a fetch returns nothing new to estimate from.
+func previousInterval(feed database.Feed) time.Duration {
+	if !feed.NextFetchAt.Valid || !feed.LastFetchedAt.Valid {
+		return defaultFetchInterval
+	}
+
+	interval := feed.NextFetchAt.Time.Sub(feed.LastFetchedAt.Time)
+	return min(max(interval, minFetchInterval), maxFetchInterval)
+}
+
+// backoffInterval doubles the wait before retrying a failing feed with each
+// consecutive failure.
+func backoffInterval(failures int32) time.Duration {
+	interval := minFetchInterval
+	for i := int32(1); i < failures && interval < maxFetchInterval; i++ {
+		interval *= 2
+	}
+
+	return min(interval, maxFetchInterval)
+}
+
+// updatePeriodInterval converts the syndication module's sy:updatePeriod and
+// sy:updateFrequency pair into the interval between expected updates.
+func updatePeriodInterval(period string, frequency int) time.Duration {
+	var base time.Duration
+	switch strings.ToLower(strings.TrimSpace(period)) {
+	case "hourly":
+		base = time.Hour
+	case "daily":
+		base = 24 * time.Hour
+	case "weekly":
+		base = 7 * 24 * time.Hour
+	case "monthly":
+		base = 30 * 24 * time.Hour
+	case "yearly":
+		base = 365 * 24 * time.Hour
+	default:
+		return 0
+	}
+
+	if frequency <= 0 {
+		frequency = 1
+	}
+
+	return base / time.Duration(frequency)
+}
+
+func parseSkipDays(days []string) []time.Weekday {
+	weekdays := []time.Weekday{}
+	for _, day := range days {
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.EqualFold(strings.TrimSpace(day), weekday.String()) {
+				weekdays = append(weekdays, weekday)
+			}
+		}
+	}
+
+	return weekdays
+}
+
+// observedInterval estimates the posting frequency of a feed from the average
+// gap between its most recent items and now, so feeds that have gone quiet
+// are polled less often even if they used to post frequently.
+func observedInterval(data FeedData, now time.Time) (time.Duration, bool) {
+	dates := []time.Time{}
+	for _, item := range data.Items {
+		pubDate, err := parsePubDate(item.PubDate)
+		if err == nil && !pubDate.After(now) {
+			dates = append(dates, pubDate)
+		}
+	}
+
+	if len(dates) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+	if len(dates) > scheduleSampleSize {
+		dates = dates[:scheduleSampleSize]
+	}
+
+	total := now.Sub(dates[len(dates)-1])
+	return total / time.Duration(len(dates)), true
+}
+
+// nextFetchTime decides when a feed should be polled again, based on how
+// often it posts and on the publisher's ttl, sy:updatePeriod, skipHours and
+// skipDays hints.
+func nextFetchTime(data FeedData, now time.Time) time.Time {
+	interval, ok := observedInterval(data, now)
+	if !ok {
+		interval = defaultFetchInterval
+	}
+
+	interval = max(interval, data.TTL, data.UpdateInterval)
+	interval = min(max(interval, minFetchInterval), maxFetchInterval)
+
+	return skipUnavailable(now.Add(interval), data.SkipHours, data.SkipDays)
+}
+
+// skipUnavailable moves next forward, an hour at a time, until it falls
+// outside the feed's skipHours and skipDays. Both are expressed in GMT.
+func skipUnavailable(next time.Time, skipHours []int, skipDays []time.Weekday) time.Time {
+	next = next.UTC()
+
+	for range 7 * 24 {
+		if !containsHour(skipHours, next.Hour()) && !containsWeekday(skipDays, next.Weekday()) {
+			return next
+		}
+
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	return next
+}
+
+func containsHour(hours []int, hour int) bool {
+	for _, h := range hours {
+		if h%24 == hour {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, w := range weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextFetchTimeObservedFrequency(t *testing.T) {
+	now := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+
+	busy := FeedData{
+		Items: []FeedItem{
+			{PubDate: "Wed, 05 Jun 2024 11:50:00 +0000"},
+			{PubDate: "Wed, 05 Jun 2024 11:40:00 +0000"},
+			{PubDate: "Wed, 05 Jun 2024 11:30:00 +0000"},
+		},
+	}
+
+	next := nextFetchTime(busy, now)
+	if next.Sub(now) != 10*time.Minute {
+		t.Fatalf("Invalid interval for busy feed: expected 10m got %v", next.Sub(now))
+	}
+
+	dormant := FeedData{
+		Items: []FeedItem{
+			{PubDate: "Wed, 05 Jun 2024 00:00:00 +0000"},
+			{PubDate: "Wed, 01 May 2024 00:00:00 +0000"},
+		},
+	}
+
+	next = nextFetchTime(dormant, now)
+	if next.Sub(now) != maxFetchInterval {
+		t.Fatalf("Invalid interval for dormant feed: expected %v got %v", maxFetchInterval, next.Sub(now))
+	}
+}
+
+func TestNextFetchTimePublisherHints(t *testing.T) {
+	now := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC) // Wednesday
+
+	data := FeedData{
+		TTL: 90 * time.Minute,
+	}
+
+	next := nextFetchTime(data, now)
+	if next.Sub(now) != 90*time.Minute {
+		t.Fatalf("Invalid interval: expected ttl of 90m got %v", next.Sub(now))
+	}
+
+	data = FeedData{
+		UpdateInterval: updatePeriodInterval("hourly", 2),
+		SkipHours:      []int{12, 13, 14},
+	}
+
+	next = nextFetchTime(data, now)
+	expected := time.Date(2024, 6, 5, 15, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("Invalid next fetch: expected %v got %v", expected, next)
+	}
+
+	data = FeedData{
+		SkipDays: parseSkipDays([]string{"Wednesday", "Thursday"}),
+	}
+
+	next = nextFetchTime(data, now)
+	expected = time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("Invalid next fetch: expected %v got %v", expected, next)
+	}
+}
+
+func TestBackoffInterval(t *testing.T) {
+	cases := []struct {
+		failures int32
+		expected time.Duration
+	}{
+		{1, 5 * time.Minute},
+		{2, 10 * time.Minute},
+		{4, 40 * time.Minute},
+		{9, 21*time.Hour + 20*time.Minute},
+		{10, maxFetchInterval},
+		{50, maxFetchInterval},
+	}
+
+	for _, c := range cases {
+		interval := backoffInterval(c.failures)
+		if interval != c.expected {
+			t.Fatalf("Invalid backoff for %d failures: expected %v got %v", c.failures, c.expected, interval)
+		}
+	}
+}
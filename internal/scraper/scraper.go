@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/xml"
@@ -9,143 +10,339 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
 
-	"github.com/PFrek/gorss/internal/api"
 	"github.com/PFrek/gorss/internal/database"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"golang.org/x/net/html/charset"
 )
 
-type FeedItem struct {
-	XMLName     xml.Name `xml:"item"`
-	Title       string   `xml:"title"`
-	Link        string   `xml:"link"`
-	Description *string  `xml:"description"`
-	PubDate     string   `xml:"pubDate"`
+type RSSItem struct {
+	PodcastExtensions
+	XMLName     xml.Name       `xml:"item"`
+	GUID        string         `xml:"guid"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description *string        `xml:"description"`
+	PubDate     string         `xml:"pubDate"`
+	Content     *string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string         `xml:"author"`
+	Creator     string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string       `xml:"category"`
+	Comments    string         `xml:"comments"`
+	Enclosures  []RSSEnclosure `xml:"enclosure"`
 }
 
-type FeedData struct {
-	XMLName xml.Name   `xml:"rss"`
-	Items   []FeedItem `xml:"channel>item"`
+type RSSEnclosure struct {
+	Url    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
 }
 
-type CachedFeed struct {
-	Data         FeedData
-	LastCachedAt time.Time
+type RSSFeed struct {
+	XMLName         xml.Name   `xml:"rss"`
+	Title           string     `xml:"channel>title"`
+	TTL             int        `xml:"channel>ttl"`
+	SkipHours       []int      `xml:"channel>skipHours>hour"`
+	SkipDays        []string   `xml:"channel>skipDays>day"`
+	UpdatePeriod    string     `xml:"channel>updatePeriod"`
+	UpdateFrequency int        `xml:"channel>updateFrequency"`
+	AtomLinks       []AtomLink `xml:"http://www.w3.org/2005/Atom channel>link"`
+	Items           []RSSItem  `xml:"channel>item"`
 }
 
-type CacheHitError struct{}
-
-func (e CacheHitError) Error() string {
-	return "Cache hit"
-}
-
-type Scraper struct {
-	Config        api.ApiConfig
-	Cache         map[string]CachedFeed
-	CacheInterval time.Duration
-	mux           sync.Mutex
-}
+func (item RSSItem) toFeedItem() FeedItem {
+	author := item.Author
+	if author == "" {
+		author = item.Creator
+	}
 
-func (s *Scraper) shouldFetch(feed database.Feed) bool {
-	if !feed.LastFetchedAt.Valid {
-		return true
+	enclosures := []Enclosure{}
+	for _, enclosure := range item.Enclosures {
+		enclosures = append(enclosures, Enclosure{
+			Url:    enclosure.Url,
+			Type:   enclosure.Type,
+			Length: enclosure.Length,
+		})
 	}
 
-	cachedFeed, ok := s.Cache[feed.Url]
-	if !ok {
-		return true
+	feedItem := FeedItem{
+		GUID:        item.GUID,
+		Title:       item.Title,
+		Link:        item.Link,
+		Description: item.Description,
+		PubDate:     item.PubDate,
+		Content:     item.Content,
+		Author:      author,
+		Categories:  item.Categories,
+		CommentsURL: item.Comments,
+		Enclosures:  enclosures,
 	}
+	item.PodcastExtensions.apply(&feedItem)
 
-	return time.Since(cachedFeed.LastCachedAt) >= s.CacheInterval
+	return feedItem
 }
 
-func (s *Scraper) checkCache(feed database.Feed) error {
-	s.mux.Lock()
-	defer s.mux.Unlock()
-
-	if !s.shouldFetch(feed) {
-		return CacheHitError{}
+func (feed RSSFeed) toFeedData() FeedData {
+	feedData := FeedData{
+		Title:          feed.Title,
+		TTL:            time.Duration(feed.TTL) * time.Minute,
+		UpdateInterval: updatePeriodInterval(feed.UpdatePeriod, feed.UpdateFrequency),
+		SkipHours:      feed.SkipHours,
+		SkipDays:       parseSkipDays(feed.SkipDays),
+		HubURL:         findLink(feed.AtomLinks, "hub"),
+		SelfURL:        findLink(feed.AtomLinks, "self"),
 	}
+	for _, item := range feed.Items {
+		feedData.Items = append(feedData.Items, item.toFeedItem())
+	}
+
+	return feedData
+}
 
-	cachedFeed := s.Cache[feed.Url]
-	cachedFeed.LastCachedAt = time.Now().UTC()
-	s.Cache[feed.Url] = cachedFeed // Update the cache time first so others know it's already being cached
+type NotModifiedError struct{}
 
-	return nil
+func (e NotModifiedError) Error() string {
+	return "Not modified"
 }
 
-func (s *Scraper) updateCacheData(feed database.Feed, feedData FeedData) {
-	s.mux.Lock()
-	defer s.mux.Unlock()
+const defaultMaxFailures = 10
 
-	cachedFeed := s.Cache[feed.Url]
-	cachedFeed.Data = feedData
-	s.Cache[feed.Url] = cachedFeed // Update the cache data when we finally get it
+type Scraper struct {
+	DB *database.Queries
+	// Connection DB runs on, used for updates spanning several statements.
+	Conn    *sql.DB
+	Parsers *ParserRegistry
+	// Sends feed, discovery and WebSub requests. Defaults to an HTTPFetcher
+	// with the default FetcherConfig.
+	Fetcher Fetcher
+	// Consecutive failed fetches after which a feed is marked dead and no
+	// longer polled. Defaults to defaultMaxFailures.
+	MaxFailures int
+	// Public base URL of the WebSub callback endpoint. When set, feeds that
+	// advertise a hub are subscribed to instead of polled.
+	WebSubCallbackURL string
+	// Maximum number of feeds fetched at once, independent of how many are
+	// picked per cycle. Defaults to defaultWorkers.
+	Workers int
+	// Maximum number of feeds fetched at once from the same host, and the
+	// minimum time between two requests to it. Default to
+	// defaultHostConcurrency and defaultHostDelay. Each instance scraping
+	// the database applies them on its own, so running several multiplies
+	// the load on a host.
+	HostConcurrency int
+	HostDelay       time.Duration
+	// Skip feeds their host's robots.txt disallows for the fetcher's
+	// User-Agent. Off by default.
+	RespectRobots bool
+	// How long a claimed feed is reserved for this instance from when its
+	// fetch starts, before other instances may claim it again should this
+	// one stop before releasing it. Defaults to defaultClaimTimeout.
+	ClaimTimeout time.Duration
+	hosts        hostLimiter
+	robots       robotsCache
+	status       statusTracker
 }
 
-func (s *Scraper) fetchDataFromFeed(feed database.Feed) (FeedData, error) {
-	err := s.checkCache(feed)
+// inTx runs fn with queries bound to a transaction, which is committed if fn
+// succeeds and rolled back otherwise.
+func (s *Scraper) inTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := s.Conn.BeginTx(ctx, nil)
 	if err != nil {
-		// Cache hit
-		_, markErr := s.Config.DB.MarkFeedFetched(context.Background(), feed.ID)
-		if markErr != nil {
-			log.Printf("Error marking feed as fetched: %v\n", markErr)
-		}
-		return FeedData{}, err
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(s.DB.WithTx(tx))
+	if err != nil {
+		return err
 	}
 
+	return tx.Commit()
+}
+
+func (s *Scraper) fetchDataFromFeed(feed database.Feed, attempt *fetchAttempt) (FeedData, error) {
 	req, err := http.NewRequest("GET", feed.Url, nil)
 	if err != nil {
 		return FeedData{}, errors.New("failed to create GET request")
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	if feed.Etag.Valid {
+		req.Header.Set("If-None-Match", feed.Etag.String)
+	}
+	if feed.LastModified.Valid {
+		req.Header.Set("If-Modified-Since", feed.LastModified.String)
+	}
+
+	resp, err := s.do(req)
 	if err != nil {
-		return FeedData{}, fmt.Errorf("failed HTTP request: %v", err)
+		return FeedData{}, fmt.Errorf("failed HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 400 {
+		attempt.PermanentURL = permanentRedirect(resp)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		_, err = s.DB.MarkFeedFetched(context.Background(), feed.ID)
+		if err != nil {
+			log.Printf("Error marking feed as fetched: %v\n", err)
+		}
+		return FeedData{}, NotModifiedError{}
+	}
+
+	if retryAfter, ok := retryAfterError(resp, time.Now().UTC()); ok {
+		return FeedData{}, retryAfter
+	}
+
 	if resp.StatusCode >= 400 {
 		return FeedData{}, fmt.Errorf("failed HTTP request: status %v", resp.Status)
 	} else {
 		log.Println("Response:", resp.Status)
 	}
 
-	feedData, err := parseXML(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return FeedData{}, fmt.Errorf("failed to read response body: %v", err)
+	}
+	attempt.Bytes = len(body)
+
+	feedData, err := s.parsers().Parse(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return FeedData{}, fmt.Errorf("failed to parse feed: %v", err)
+	}
+
+	if len(feedData.Recoveries) > 0 {
+		log.Printf("Recovered malformed feed %s: %s\n", feed.Url, strings.Join(feedData.Recoveries, ", "))
+		attempt.Recoveries = feedData.Recoveries
+	}
 
-	s.updateCacheData(feed, feedData)
+	err = s.DB.UpdateFeedValidators(context.Background(), database.UpdateFeedValidatorsParams{
+		ID:           feed.ID,
+		Etag:         headerToNullString(resp.Header, "ETag"),
+		LastModified: headerToNullString(resp.Header, "Last-Modified"),
+	})
+	if err != nil {
+		log.Printf("Error saving feed validators: %v\n", err)
+	}
 
-	_, err = s.Config.DB.MarkFeedFetched(context.Background(), feed.ID)
+	_, err = s.DB.MarkFeedFetched(context.Background(), feed.ID)
 	if err != nil {
 		log.Printf("Error marking feed as fetched: %v\n", err)
 	}
 	return feedData, nil
 }
 
-func parseXML(xmlData io.Reader) (FeedData, error) {
-	var feedData FeedData
+func headerToNullString(header http.Header, key string) sql.NullString {
+	return stringToNullString(header.Get(key))
+}
+
+func stringToNullString(value string) sql.NullString {
+	return sql.NullString{
+		String: value,
+		Valid:  value != "",
+	}
+}
+
+func (s *Scraper) parsers() *ParserRegistry {
+	if s.Parsers == nil {
+		return DefaultParserRegistry()
+	}
+
+	return s.Parsers
+}
 
-	decoder := xml.NewDecoder(xmlData)
-	err := decoder.Decode(&feedData)
+// parseXML parses an RSS, Atom or RDF document. Documents the strict decoder
+// rejects are retried in recovery mode, see recoverXML.
+func parseXML(xmlData io.Reader) (FeedData, error) {
+	data, err := io.ReadAll(xmlData)
 	if err != nil {
 		return FeedData{}, err
 	}
 
+	feedData, err := decodeXML(data, false)
+	if err != nil {
+		return recoverXML(data, err)
+	}
+
 	return feedData, nil
 }
 
+// decodeXML decodes a feed document. In lenient mode the decoder accepts
+// unescaped ampersands, unclosed tags and HTML entities such as &nbsp;.
+// HTML's void elements aren't auto-closed, as they include RSS's <link>.
+func decodeXML(data []byte, lenient bool) (FeedData, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if lenient {
+		decoder.Strict = false
+		decoder.Entity = xml.HTMLEntity
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return FeedData{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case start.Name.Local == "rss":
+			var rssFeed RSSFeed
+			err = decoder.DecodeElement(&rssFeed, &start)
+			if err != nil {
+				return FeedData{}, err
+			}
+
+			return rssFeed.toFeedData(), nil
+
+		case start.Name.Space == atomNamespace && start.Name.Local == "feed":
+			var atomFeed AtomFeed
+			err = decoder.DecodeElement(&atomFeed, &start)
+			if err != nil {
+				return FeedData{}, err
+			}
+
+			return atomFeed.toFeedData(), nil
+
+		case start.Name.Space == rdfNamespace && start.Name.Local == "RDF":
+			var rdfFeed RDFFeed
+			err = decoder.DecodeElement(&rdfFeed, &start)
+			if err != nil {
+				return FeedData{}, err
+			}
+
+			return rdfFeed.toFeedData(), nil
+
+		default:
+			return FeedData{}, fmt.Errorf("unsupported feed format: <%s>", start.Name.Local)
+		}
+	}
+}
+
+// Start checks for feeds due for fetching every interval, fetching at most
+// numFeeds of them per cycle. Each feed is only fetched once its own
+// next_fetch_at has passed.
 func (s *Scraper) Start(interval time.Duration, numFeeds int) chan bool {
 	ticker := time.NewTicker(interval)
 	done := make(chan bool)
 
 	log.Printf("Starting Scraper with interval %v and limit %d", interval, numFeeds)
+	s.status.start(interval, s.claimDuration(numFeeds))
 	go func() {
 		for {
 			select {
 			case <-done:
+				ticker.Stop()
+				s.status.stop()
 				return
 
 			case <-ticker.C:
@@ -159,105 +356,236 @@ func (s *Scraper) Start(interval time.Duration, numFeeds int) chan bool {
 
 func (s *Scraper) scrape(numFeeds int) {
 	log.Println("Finding feeds in need of fetching...")
-	feedsToFetch, err := s.Config.DB.GetNextFeedsToFetch(context.Background(), int32(numFeeds))
+	s.status.beginCycle()
+	feedsToFetch, err := s.claimFeeds(numFeeds)
 	if err != nil {
 		log.Printf("Error getting feeds to fetch: %v", err)
+		s.status.endCycle(0, err)
 		return
 	}
 
 	if len(feedsToFetch) == 0 {
 		log.Println("No feeds in need of fetching. Waiting for next cycle...")
+		s.status.endCycle(0, nil)
 		return
 	}
 
-	var wg sync.WaitGroup
-
-	for _, feed := range feedsToFetch {
-		wg.Add(1)
+	s.fetchAll(feedsToFetch)
+	s.status.endCycle(len(feedsToFetch), nil)
+	log.Println("Finished processing feeds. Waiting for next cycle...")
+}
 
-		go func(feed database.Feed) {
-			defer wg.Done()
+// fetchFeed fetches and stores a feed, returning the error the fetch failed
+// with, if any.
+func (s *Scraper) fetchFeed(feed database.Feed) error {
+	log.Println("Fetching feed from ", feed.Url)
 
-			log.Println("Fetching feed from ", feed.Url)
-			feedData, err := s.fetchDataFromFeed(feed)
-			if err != nil {
-				if errors.Is(err, CacheHitError{}) {
-					log.Println(err)
-					return
-				}
+	attempt := fetchAttempt{
+		StartedAt: time.Now().UTC(),
+	}
 
-				log.Printf("Error fetching %s: %v\n", feed.Url, err)
-				return
+	feedData, err := s.fetchDataFromFeed(feed, &attempt)
+	if err != nil {
+		if errors.Is(err, NotModifiedError{}) {
+			log.Println(err)
+			s.scheduleNextFetch(feed, time.Now().UTC().Add(previousInterval(feed)))
+			s.recordAttempt(feed, attempt, nil)
+			if s.handleMove(feed, attempt) != nil {
+				return nil
 			}
+			s.subscribeWebSub(feed, nil)
+			return nil
+		}
+
+		// Feeds robots.txt disallows are retried once it is fetched again.
+		var disallowed RobotsDisallowedError
+		if errors.As(err, &disallowed) {
+			log.Println(err)
+			s.recordSkipped(feed, err, disallowed.Until)
+			s.recordAttempt(feed, attempt, err)
+			return nil
+		}
 
-			s.processFeed(feedData, feed.ID)
-		}(feed)
+		log.Printf("Error fetching %s: %v\n", feed.Url, err)
+
+		// Being rate limited says nothing about the feed's health, so it
+		// doesn't count towards marking it dead.
+		var retryAfter RetryAfterError
+		if errors.As(err, &retryAfter) {
+			s.recordSkipped(feed, err, retryAfter.Until)
+		} else {
+			s.recordFailure(feed, err)
+		}
+		s.recordAttempt(feed, attempt, err)
+		return err
 	}
 
-	wg.Wait()
-	log.Println("Finished processing feeds. Waiting for next cycle...")
+	attempt.ItemsFound = len(feedData.Items)
+	s.adoptLegacyGuids(feed, feedData)
+	attempt.ItemsInserted = s.processFeed(feedData, feed.ID)
+	s.scheduleNextFetch(feed, nextFetchTime(feedData, time.Now().UTC()))
+	s.recordAttempt(feed, attempt, nil)
+	if s.handleMove(feed, attempt) != nil {
+		return nil
+	}
+	s.subscribeWebSub(feed, &feedData)
+	return nil
 }
 
-func parsePubDate(pubDate string) (time.Time, error) {
-	formats := []string{
-		time.RFC1123,
-		time.RFC1123Z,
+// handleMove follows a permanent redirect of the feed. It returns
+// ErrFeedMerged when the feed was merged into another one and deleted, in
+// which case nothing more must be done with it.
+func (s *Scraper) handleMove(feed database.Feed, attempt fetchAttempt) error {
+	if attempt.PermanentURL == "" || attempt.PermanentURL == feed.Url {
+		return nil
 	}
 
-	var converted *time.Time
-	for _, format := range formats {
-		result, err := time.Parse(format, pubDate)
-		if err == nil {
-			converted = new(time.Time)
-			*converted = result
-			break
-		}
+	return s.updateFeedUrl(feed, attempt.PermanentURL)
+}
+
+// IngestFeed stores the posts of a feed fetched outside of the scraping
+// cycle, e.g. when it was registered, along with its validators, and
+// schedules its next fetch as if the scraper had just fetched it. WebSub
+// subscriptions are left to that fetch.
+func (s *Scraper) IngestFeed(feed database.Feed, fetched FetchedFeed) int {
+	inserted := s.processFeed(fetched.Data, feed.ID)
+
+	err := s.DB.UpdateFeedValidators(context.Background(), database.UpdateFeedValidatorsParams{
+		ID:           feed.ID,
+		Etag:         fetched.Etag,
+		LastModified: fetched.LastModified,
+	})
+	if err != nil {
+		log.Printf("Error saving feed validators: %v\n", err)
+	}
+
+	_, err = s.DB.MarkFeedFetched(context.Background(), feed.ID)
+	if err != nil {
+		log.Printf("Error marking feed as fetched: %v\n", err)
+	}
+	s.scheduleNextFetch(feed, nextFetchTime(fetched.Data, time.Now().UTC()))
+
+	return inserted
+}
+
+func (s *Scraper) scheduleNextFetch(feed database.Feed, next time.Time) {
+	err := s.DB.SetFeedNextFetch(context.Background(), database.SetFeedNextFetchParams{
+		ID: feed.ID,
+		NextFetchAt: sql.NullTime{
+			Time:  next,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		log.Printf("Error scheduling next fetch for %s: %v\n", feed.Url, err)
 	}
+}
 
-	if converted == nil {
-		return time.Time{}, fmt.Errorf("Failed to convert date from string:, %s", pubDate)
+func (s *Scraper) maxFailures() int {
+	if s.MaxFailures <= 0 {
+		return defaultMaxFailures
 	}
 
-	return converted.UTC(), nil
+	return s.MaxFailures
 }
 
-func (s *Scraper) processFeed(data FeedData, feedID uuid.UUID) {
+func (s *Scraper) recordFailure(feed database.Feed, fetchErr error) {
+	next := time.Now().UTC().Add(backoffInterval(feed.FailureCount + 1))
+
+	updated, err := s.DB.RecordFeedFailure(context.Background(), database.RecordFeedFailureParams{
+		LastError: sql.NullString{
+			String: fetchErr.Error(),
+			Valid:  true,
+		},
+		NextFetchAt: sql.NullTime{
+			Time:  next,
+			Valid: true,
+		},
+		MaxFailures: int32(s.maxFailures()),
+		ID:          feed.ID,
+	})
+	if err != nil {
+		log.Printf("Error recording failure for %s: %v\n", feed.Url, err)
+		return
+	}
+
+	if updated.Dead {
+		log.Printf("Feed %s failed %d times in a row, marking as dead\n", feed.Url, updated.FailureCount)
+	}
+}
+
+// recordSkipped keeps a feed that couldn't be fetched for now in the
+// schedule, recording why in its error state and retrying it at next. Unlike
+// recordFailure it doesn't count towards marking the feed dead, as the
+// publisher is expected to let us fetch it again.
+func (s *Scraper) recordSkipped(feed database.Feed, reason error, next time.Time) {
+	_, err := s.DB.RecordFeedSkipped(context.Background(), database.RecordFeedSkippedParams{
+		LastError: sql.NullString{
+			String: reason.Error(),
+			Valid:  true,
+		},
+		NextFetchAt: sql.NullTime{
+			Time:  next,
+			Valid: true,
+		},
+		ID: feed.ID,
+	})
+	if err != nil {
+		log.Printf("Error recording skipped feed %s: %v\n", feed.Url, err)
+	}
+}
+
+// processFeed stores the feed's items as posts and returns how many were new.
+func (s *Scraper) processFeed(data FeedData, feedID uuid.UUID) int {
 	fmt.Printf("Found %d entries\n", len(data.Items))
 
+	inserted := 0
+
 	for _, item := range data.Items {
 		currentTime := time.Now().UTC()
 
+		// Posts without a usable date are kept, dated when first seen.
 		pubDate, err := parsePubDate(item.PubDate)
 		if err != nil {
 			log.Println(err)
-			log.Println("Skipping Post:", item.Title)
-			continue
-		}
-
-		description := sql.NullString{
-			String: "",
-			Valid:  false,
+			log.Println("Using first-seen time for Post:", item.Title)
+			pubDate = currentTime
 		}
 
-		if item.Description != nil {
-			description.String = *item.Description
-			description.Valid = true
+		// Descriptions and content come from third parties, so only their
+		// sanitized HTML is stored, along with a plain-text rendition.
+		description, descriptionText := sanitizedRenditions(item.Description, item.Link)
+		content, contentText := sanitizedRenditions(item.Content, item.Link)
+
+		params := database.CreatePostParams{
+			ID:                 uuid.New(),
+			CreatedAt:          currentTime,
+			UpdatedAt:          currentTime,
+			Title:              item.Title,
+			Url:                cleanLink(item.Link),
+			Description:        description,
+			PublishedAt:        pubDate,
+			FeedID:             feedID,
+			Guid:               itemGUID(item),
+			ContentHash:        contentHash(item),
+			Content:            content,
+			Author:             stringToNullString(item.Author),
+			CommentsUrl:        stringToNullString(item.CommentsURL),
+			DurationSeconds:    intToNullInt32(item.Duration),
+			Episode:            intToNullInt32(item.Episode),
+			Season:             intToNullInt32(item.Season),
+			ImageUrl:           stringToNullString(item.ImageURL),
+			Explicit:           boolPtrToNullBool(item.Explicit),
+			DescriptionText:    descriptionText,
+			ContentText:        contentText,
+			ContentHashVersion: contentHashVersion,
 		}
 
-		_, err = s.Config.DB.CreatePost(context.Background(), database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   currentTime,
-			UpdatedAt:   currentTime,
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: description,
-			PublishedAt: pubDate,
-			FeedID:      feedID,
-		})
+		post, err := s.DB.CreatePost(context.Background(), params)
 		if err != nil {
 			if err, ok := err.(*pq.Error); ok {
 				if err.Code.Name() == "unique_violation" {
-					log.Println("Post with URL already in DB, skipping.")
+					s.updateEditedPost(params, item)
 					continue
 				}
 			}
@@ -266,6 +594,11 @@ func (s *Scraper) processFeed(data FeedData, feedID uuid.UUID) {
 			continue
 		}
 
+		savePostExtras(context.Background(), s.DB, post.ID, item)
+
 		log.Println("Saved Post to DB:", item.Title)
+		inserted++
 	}
+
+	return inserted
 }
@@ -1,8 +1,15 @@
 package scraper
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
 )
 
 // TESTS
@@ -78,3 +85,291 @@ func TestParseXMLManyEntries(t *testing.T) {
 		t.Fatalf("Invalid title: expected '3rd entry' got '%s'", feedData.Items[0].Title)
 	}
 }
+
+func TestParseXMLAtom(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Atom feed</title>
+	<entry>
+		<title>1st entry</title>
+		<link rel="self" href="https://example.com/entry-1.atom"/>
+		<link rel="alternate" href="https://example.com/entry-1"/>
+		<summary>Entry summary</summary>
+		<updated>2024-06-05T10:00:00Z</updated>
+		<published>2024-06-04T10:00:00Z</published>
+	</entry>
+	<entry>
+		<title>2nd entry</title>
+		<link href="https://example.com/entry-2"/>
+		<content type="xhtml"><div>Entry <b>content</b></div></content>
+		<updated>2024-06-06T10:00:00+02:00</updated>
+	</entry>
+</feed>`
+
+	reader := strings.NewReader(xml)
+
+	feedData, err := parseXML(reader)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(feedData.Items) != 2 {
+		t.Fatalf("Invalid number of items expected 2 got %d", len(feedData.Items))
+	}
+
+	first := feedData.Items[0]
+	if first.Link != "https://example.com/entry-1" {
+		t.Fatalf("Invalid link: expected alternate link got '%s'", first.Link)
+	}
+
+	if first.Description == nil || *first.Description != "Entry summary" {
+		t.Fatalf("Invalid description: expected 'Entry summary' got %v", first.Description)
+	}
+
+	if first.PubDate != "2024-06-04T10:00:00Z" {
+		t.Fatalf("Invalid pubDate: expected published date got '%s'", first.PubDate)
+	}
+
+	second := feedData.Items[1]
+	if second.Description == nil || *second.Description != "<div>Entry <b>content</b></div>" {
+		t.Fatalf("Invalid description: expected xhtml content got %v", second.Description)
+	}
+
+	pubDate, err := parsePubDate(second.PubDate)
+	if err != nil {
+		t.Fatalf("Failed to parse updated date: %v", err)
+	}
+
+	if !pubDate.Equal(time.Date(2024, 6, 6, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Invalid pubDate: got %v", pubDate)
+	}
+}
+
+func TestParserRegistryJSONFeed(t *testing.T) {
+	json := `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "JSON feed",
+	"items": [
+		{
+			"id": "1",
+			"url": "https://example.com/entry-1",
+			"title": "1st entry",
+			"content_html": "<p>Entry content</p>",
+			"date_published": "2024-06-05T00:00:00Z"
+		},
+		{
+			"id": "2",
+			"url": "https://example.com/entry-2",
+			"title": "2nd entry",
+			"content_text": "Plain content",
+			"date_published": "2024-06-06T00:00:00Z"
+		}
+	]
+}`
+
+	feedData, err := DefaultParserRegistry().Parse("application/json", []byte(json))
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(feedData.Items) != 2 {
+		t.Fatalf("Invalid number of items expected 2 got %d", len(feedData.Items))
+	}
+
+	if feedData.Items[0].Description == nil || *feedData.Items[0].Description != "<p>Entry content</p>" {
+		t.Fatalf("Invalid description: expected content_html got %v", feedData.Items[0].Description)
+	}
+
+	if feedData.Items[1].Description == nil || *feedData.Items[1].Description != "Plain content" {
+		t.Fatalf("Invalid description: expected content_text got %v", feedData.Items[1].Description)
+	}
+
+	if feedData.Items[1].Link != "https://example.com/entry-2" {
+		t.Fatalf("Invalid link: expected 'https://example.com/entry-2' got '%s'", feedData.Items[1].Link)
+	}
+}
+
+func TestParseXMLRDF(t *testing.T) {
+	xml := `<?xml version="1.0"?>
+<rdf:RDF
+	xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns="http://purl.org/rss/1.0/">
+	<channel rdf:about="https://example.com/">
+		<title>RDF feed</title>
+		<items>
+			<rdf:Seq>
+				<rdf:li resource="https://example.com/entry-1"/>
+				<rdf:li resource="https://example.com/entry-2"/>
+			</rdf:Seq>
+		</items>
+	</channel>
+	<item rdf:about="https://example.com/entry-1">
+		<title>1st entry</title>
+		<link>https://example.com/entry-1</link>
+		<description>Entry description</description>
+		<dc:date>2024-06-05T10:30+02:00</dc:date>
+	</item>
+	<item rdf:about="https://example.com/entry-2">
+		<title>2nd entry</title>
+		<link>https://example.com/entry-2</link>
+		<dc:date>2024-06-06</dc:date>
+	</item>
+</rdf:RDF>`
+
+	reader := strings.NewReader(xml)
+
+	feedData, err := parseXML(reader)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(feedData.Items) != 2 {
+		t.Fatalf("Invalid number of items expected 2 got %d", len(feedData.Items))
+	}
+
+	if feedData.Items[0].Title != "1st entry" {
+		t.Fatalf("Invalid title: expected '1st entry' got '%s'", feedData.Items[0].Title)
+	}
+
+	pubDate, err := parsePubDate(feedData.Items[0].PubDate)
+	if err != nil {
+		t.Fatalf("Failed to parse dc:date: %v", err)
+	}
+
+	if !pubDate.Equal(time.Date(2024, 6, 5, 8, 30, 0, 0, time.UTC)) {
+		t.Fatalf("Invalid pubDate: got %v", pubDate)
+	}
+
+	_, err = parsePubDate(feedData.Items[1].PubDate)
+	if err != nil {
+		t.Fatalf("Failed to parse dc:date: %v", err)
+	}
+}
+
+type changelogParser struct{}
+
+func (changelogParser) Detect(contentType string, data []byte) bool {
+	return contentType == "text/x-changelog"
+}
+
+func (changelogParser) Parse(data []byte) (FeedData, error) {
+	feedData := FeedData{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		feedData.Items = append(feedData.Items, FeedItem{Title: line})
+	}
+
+	return feedData, nil
+}
+
+func TestParserRegistryCustomParser(t *testing.T) {
+	registry := DefaultParserRegistry()
+	registry.Register(changelogParser{})
+
+	feedData, err := registry.Parse("text/x-changelog", []byte("v1.0.0\nv1.1.0\n"))
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if len(feedData.Items) != 2 {
+		t.Fatalf("Invalid number of items expected 2 got %d", len(feedData.Items))
+	}
+
+	_, err = registry.Parse("text/html", []byte("<html><body></body></html>"))
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Expected ErrUnsupportedFormat got %v", err)
+	}
+}
+
+func TestParseXMLItemExtras(t *testing.T) {
+	xml := `<rss version="2.0"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+	<item>
+		<title>Episode 1</title>
+		<link>https://example.com/episode-1</link>
+		<description>Short description</description>
+		<content:encoded><![CDATA[<p>Full <em>content</em></p>]]></content:encoded>
+		<dc:creator>Jane Doe</dc:creator>
+		<category>Go</category>
+		<category>Podcasts</category>
+		<comments>https://example.com/episode-1#comments</comments>
+		<enclosure url="https://example.com/episode-1.mp3" type="audio/mpeg" length="12345"/>
+		<pubDate>Wed, 05 Jun 2024 00:00:00 +0000</pubDate>
+	</item>
+</channel>
+</rss>`
+
+	reader := strings.NewReader(xml)
+
+	feedData, err := parseXML(reader)
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	item := feedData.Items[0]
+	if item.Content == nil || *item.Content != "<p>Full <em>content</em></p>" {
+		t.Fatalf("Invalid content: got %v", item.Content)
+	}
+
+	if item.Author != "Jane Doe" {
+		t.Fatalf("Invalid author: expected 'Jane Doe' got '%s'", item.Author)
+	}
+
+	if len(item.Categories) != 2 || item.Categories[1] != "Podcasts" {
+		t.Fatalf("Invalid categories: got %v", item.Categories)
+	}
+
+	if item.CommentsURL != "https://example.com/episode-1#comments" {
+		t.Fatalf("Invalid comments URL: got '%s'", item.CommentsURL)
+	}
+
+	if len(item.Enclosures) != 1 {
+		t.Fatalf("Invalid number of enclosures expected 1 got %d", len(item.Enclosures))
+	}
+
+	enclosure := item.Enclosures[0]
+	if enclosure.Url != "https://example.com/episode-1.mp3" || enclosure.Type != "audio/mpeg" || enclosure.Length != 12345 {
+		t.Fatalf("Invalid enclosure: got %+v", enclosure)
+	}
+}
+
+func TestIngestFeed(t *testing.T) {
+	db, conn := newFakeDB(t)
+	posts := handlePosts(db)
+
+	var etag, nextFetchAt driver.Value
+	db.handle("UpdateFeedValidators", func(query string, args []driver.Value) ([]fakeRow, error) {
+		etag = args[1]
+		return nil, nil
+	})
+	db.handle("MarkFeedFetched", func(query string, args []driver.Value) ([]fakeRow, error) {
+		return nil, nil
+	})
+	db.handle("SetFeedNextFetch", func(query string, args []driver.Value) ([]fakeRow, error) {
+		nextFetchAt = args[1]
+		return nil, nil
+	})
+
+	scraper := Scraper{DB: database.New(conn), Conn: conn}
+	inserted := scraper.IngestFeed(database.Feed{ID: uuid.New()}, FetchedFeed{
+		Data: FeedData{
+			Items: []FeedItem{
+				{Title: "One", Link: "https://example.com/1"},
+				{Title: "Two", Link: "https://example.com/2"},
+			},
+		},
+		Etag: sql.NullString{String: `"v1"`, Valid: true},
+	})
+
+	if inserted != 2 || len(posts.rows) != 2 {
+		t.Fatalf("Expected 2 posts to be ingested, got %d inserted and %d stored", inserted, len(posts.rows))
+	}
+	if etag != `"v1"` {
+		t.Fatalf("Expected the ETag to be saved, got %v", etag)
+	}
+	if nextFetchAt == nil {
+		t.Fatalf("Expected the next fetch to be scheduled")
+	}
+}
@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"sync"
+	"time"
+)
+
+// Cycles that haven't finished within this many scraping intervals, or
+// within an interval and the time a cycle may legitimately take if longer,
+// make the scraper unhealthy.
+const unhealthyIntervals = 3
+
+// Status describes what the scraper has been doing, for health checks.
+type Status struct {
+	Running   bool
+	Interval  time.Duration
+	StartedAt time.Time
+	// How long a cycle may take, as long as its feeds are claimed for.
+	CycleTimeout time.Duration
+	Cycles       int
+	// Times of the most recent cycle. LastCycleFinishedAt is before
+	// LastCycleStartedAt while a cycle is in progress.
+	LastCycleStartedAt  time.Time
+	LastCycleFinishedAt time.Time
+	// Number of feeds claimed by the most recent finished cycle.
+	LastCycleFeeds int
+	// Error that made the most recent cycle fail, empty if it succeeded.
+	LastError string
+}
+
+// Healthy reports whether the scraper is running and has finished a cycle
+// recently, or was started recently enough not to have finished one yet.
+func (s Status) Healthy(now time.Time) bool {
+	if !s.Running {
+		return false
+	}
+
+	last := s.LastCycleFinishedAt
+	if last.IsZero() {
+		last = s.StartedAt
+	}
+
+	return now.Sub(last) <= max(unhealthyIntervals*s.Interval, s.Interval+s.CycleTimeout)
+}
+
+type statusTracker struct {
+	mu     sync.Mutex
+	status Status
+}
+
+func (t *statusTracker) start(interval time.Duration, cycleTimeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.Running = true
+	t.status.Interval = interval
+	t.status.CycleTimeout = cycleTimeout
+	t.status.StartedAt = time.Now().UTC()
+}
+
+func (t *statusTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.Running = false
+}
+
+func (t *statusTracker) beginCycle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.LastCycleStartedAt = time.Now().UTC()
+}
+
+func (t *statusTracker) endCycle(feeds int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status.Cycles++
+	t.status.LastCycleFinishedAt = time.Now().UTC()
+	t.status.LastCycleFeeds = feeds
+	t.status.LastError = ""
+	if err != nil {
+		t.status.LastError = err.Error()
+	}
+}
+
+// Status returns a snapshot of the scraper's status.
+func (s *Scraper) Status() Status {
+	s.status.mu.Lock()
+	defer s.status.mu.Unlock()
+
+	return s.status.status
+}
@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusHealthy(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		status   Status
+		expected bool
+	}{
+		{"not running", Status{Interval: time.Minute, StartedAt: now}, false},
+		{"just started", Status{Running: true, Interval: time.Minute, StartedAt: now.Add(-time.Minute)}, true},
+		{"never finished a cycle", Status{Running: true, Interval: time.Minute, StartedAt: now.Add(-time.Hour)}, false},
+		{
+			"recent cycle",
+			Status{
+				Running:             true,
+				Interval:            time.Minute,
+				StartedAt:           now.Add(-time.Hour),
+				LastCycleFinishedAt: now.Add(-2 * time.Minute),
+			},
+			true,
+		},
+		{
+			"long cycle within its timeout",
+			Status{
+				Running:             true,
+				Interval:            time.Minute,
+				StartedAt:           now.Add(-time.Hour),
+				CycleTimeout:        10 * time.Minute,
+				LastCycleStartedAt:  now.Add(-9 * time.Minute),
+				LastCycleFinishedAt: now.Add(-10 * time.Minute),
+			},
+			true,
+		},
+		{
+			"stalled cycle",
+			Status{
+				Running:             true,
+				Interval:            time.Minute,
+				StartedAt:           now.Add(-time.Hour),
+				LastCycleStartedAt:  now.Add(-10 * time.Minute),
+				LastCycleFinishedAt: now.Add(-11 * time.Minute),
+			},
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		healthy := c.status.Healthy(now)
+		if healthy != c.expected {
+			t.Fatalf("%s: expected %v got %v", c.name, c.expected, healthy)
+		}
+	}
+}
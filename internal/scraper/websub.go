@@ -0,0 +1,235 @@
+package scraper
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	webSubLeaseSeconds = 10 * 24 * 60 * 60
+
+	// Subscriptions are renewed once they are this close to expiring. It must
+	// match the window used by ClaimNextFeedsToFetch, which resumes polling
+	// the feed so the renewal happens on its next fetch.
+	webSubRenewalWindow = 24 * time.Hour
+)
+
+// needsWebSubSubscription reports whether the scraper should (re)subscribe
+// to a hub instead of relying on polling the feed.
+func (s *Scraper) needsWebSubSubscription(feed database.Feed, hub string) bool {
+	if s.WebSubCallbackURL == "" || hub == "" {
+		return false
+	}
+
+	if !feed.WebsubLeaseExpiresAt.Valid {
+		return true
+	}
+
+	return time.Until(feed.WebsubLeaseExpiresAt.Time) <= webSubRenewalWindow
+}
+
+// webSubTarget returns the hub and topic to subscribe to. Without fresh feed
+// data (e.g. after a 304) the ones from the current subscription are reused.
+func webSubTarget(feed database.Feed, feedData *FeedData) (string, string) {
+	if feedData == nil {
+		return feed.WebsubHub.String, feed.WebsubTopic.String
+	}
+
+	topic := feedData.SelfURL
+	if topic == "" {
+		topic = feed.Url
+	}
+
+	return feedData.HubURL, topic
+}
+
+func (s *Scraper) subscribeWebSub(feed database.Feed, feedData *FeedData) {
+	hub, topic := webSubTarget(feed, feedData)
+	if !s.needsWebSubSubscription(feed, hub) {
+		return
+	}
+
+	// Renewals keep the current secret so pushes signed with it stay valid.
+	secret := feed.WebsubSecret.String
+	if !feed.WebsubSecret.Valid || feed.WebsubHub.String != hub {
+		var err error
+		secret, err = newWebSubSecret()
+		if err != nil {
+			log.Printf("Error generating WebSub secret for %s: %v\n", feed.Url, err)
+			return
+		}
+	}
+
+	// Saved before the request, as hubs may verify intent before answering.
+	err := s.DB.SetFeedWebSubSubscription(context.Background(), database.SetFeedWebSubSubscriptionParams{
+		ID:           feed.ID,
+		WebsubHub:    sql.NullString{String: hub, Valid: true},
+		WebsubTopic:  sql.NullString{String: topic, Valid: true},
+		WebsubSecret: sql.NullString{String: secret, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error saving WebSub subscription for %s: %v\n", feed.Url, err)
+		return
+	}
+
+	callback := webSubCallback(s.WebSubCallbackURL, feed.ID, secret)
+	err = s.requestWebSubSubscription(context.Background(), hub, callback, topic, secret)
+	if err != nil {
+		log.Printf("Error subscribing to %s at hub %s: %v\n", topic, hub, err)
+		return
+	}
+
+	log.Printf("Requested WebSub subscription to %s at hub %s\n", topic, hub)
+}
+
+func (s *Scraper) requestWebSubSubscription(ctx context.Context, hub string, callback string, topic string, secret string) error {
+	form := url.Values{}
+	form.Set("hub.mode", "subscribe")
+	form.Set("hub.callback", callback)
+	form.Set("hub.topic", topic)
+	form.Set("hub.secret", secret)
+	form.Set("hub.lease_seconds", strconv.Itoa(webSubLeaseSeconds))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create POST request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("hub rejected subscription: status %v", resp.Status)
+	}
+
+	return nil
+}
+
+// webSubCallback returns the callback URL of a feed's subscription. It
+// carries a token derived from the secret, which only the hub learns, so
+// verifications of intent can't be forged from the public feed IDs. Renewals
+// keep the secret, and so the same callback.
+func webSubCallback(base string, feedID uuid.UUID, secret string) string {
+	return strings.TrimSuffix(base, "/") + "/" + feedID.String() + "?token=" + webSubCallbackToken(secret)
+}
+
+func webSubCallbackToken(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("callback"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidWebSubCallbackToken reports whether token is the one in the callback
+// URL of the feed's subscription.
+func ValidWebSubCallbackToken(feed database.Feed, token string) bool {
+	if !feed.WebsubSecret.Valid {
+		return false
+	}
+
+	expected := webSubCallbackToken(feed.WebsubSecret.String)
+	return hmac.Equal([]byte(token), []byte(expected))
+}
+
+// ValidWebSubPush reports whether pushed content should be processed: the
+// feed must have an active subscription, and the push must be signed with
+// its secret.
+func ValidWebSubPush(feed database.Feed, signature string, body []byte, now time.Time) bool {
+	if !feed.WebsubSecret.Valid || !feed.WebsubLeaseExpiresAt.Valid || !feed.WebsubLeaseExpiresAt.Time.After(now) {
+		return false
+	}
+
+	return ValidWebSubSignature(feed.WebsubSecret.String, signature, body)
+}
+
+func newWebSubSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// ValidWebSubSignature checks an X-Hub-Signature header ("method=hexdigest")
+// against the HMAC of the pushed body.
+func ValidWebSubSignature(secret string, signature string, body []byte) bool {
+	method, digest, ok := strings.Cut(signature, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch method {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// HandleWebSubPush processes content pushed by a hub as if the feed had just
+// been fetched, and returns the number of new posts.
+func (s *Scraper) HandleWebSubPush(feed database.Feed, contentType string, body []byte) (int, error) {
+	feedData, err := s.parsers().Parse(contentType, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse pushed content: %v", err)
+	}
+
+	s.adoptLegacyGuids(feed, feedData)
+	return s.processFeed(feedData, feed.ID), nil
+}
+
+// ConfirmWebSubLease records the lease granted by the hub once it has
+// verified our intent to subscribe, capped to the lease that was requested.
+// A zero lease ends the subscription, so the feed falls back to being polled.
+func (s *Scraper) ConfirmWebSubLease(ctx context.Context, feedID uuid.UUID, leaseSeconds int) error {
+	leaseSeconds = min(leaseSeconds, webSubLeaseSeconds)
+
+	lease := sql.NullTime{}
+	if leaseSeconds > 0 {
+		lease.Time = time.Now().UTC().Add(time.Duration(leaseSeconds) * time.Second)
+		lease.Valid = true
+	}
+
+	return s.DB.SetFeedWebSubLease(ctx, database.SetFeedWebSubLeaseParams{
+		ID:                   feedID,
+		WebsubLeaseExpiresAt: lease,
+	})
+}
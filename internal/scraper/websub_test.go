@@ -0,0 +1,169 @@
+package scraper
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/PFrek/gorss/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestRequestWebSubSubscription(t *testing.T) {
+	received := map[string]string{}
+
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err := req.ParseForm()
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		for _, key := range []string{"hub.mode", "hub.callback", "hub.topic", "hub.secret", "hub.lease_seconds"} {
+			received[key] = req.PostForm.Get(key)
+		}
+		w.WriteHeader(202)
+	}))
+	defer hub.Close()
+
+	scraper := Scraper{}
+	err := scraper.requestWebSubSubscription(context.Background(), hub.URL, "https://gorss.example.com/v1/websub/1", "https://example.com/feed", "secret")
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"hub.mode":          "subscribe",
+		"hub.callback":      "https://gorss.example.com/v1/websub/1",
+		"hub.topic":         "https://example.com/feed",
+		"hub.secret":        "secret",
+		"hub.lease_seconds": "864000",
+	}
+	for key, value := range expected {
+		if received[key] != value {
+			t.Fatalf("Invalid %s: expected '%s' got '%s'", key, value, received[key])
+		}
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(400)
+	}))
+	defer rejecting.Close()
+
+	err = scraper.requestWebSubSubscription(context.Background(), rejecting.URL, "https://gorss.example.com/v1/websub/1", "https://example.com/feed", "secret")
+	if err == nil {
+		t.Fatalf("Expected error for rejected subscription")
+	}
+}
+
+func TestValidWebSubSignature(t *testing.T) {
+	body := []byte(`<rss><channel><item><title>Pushed</title></item></channel></rss>`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !ValidWebSubSignature("secret", signature, body) {
+		t.Fatalf("Expected valid signature")
+	}
+
+	if ValidWebSubSignature("other secret", signature, body) {
+		t.Fatalf("Expected invalid signature for wrong secret")
+	}
+
+	if ValidWebSubSignature("secret", signature, []byte("tampered")) {
+		t.Fatalf("Expected invalid signature for tampered body")
+	}
+
+	if ValidWebSubSignature("secret", "md5=abcdef", body) || ValidWebSubSignature("secret", "", body) {
+		t.Fatalf("Expected invalid signature for unsupported method")
+	}
+}
+
+func TestValidWebSubPush(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+	body := []byte(`<rss><channel><item><title>Pushed</title></item></channel></rss>`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	secret := sql.NullString{String: "secret", Valid: true}
+	active := sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+	expired := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+
+	cases := []struct {
+		name      string
+		feed      database.Feed
+		signature string
+		expected  bool
+	}{
+		{"active subscription", database.Feed{WebsubSecret: secret, WebsubLeaseExpiresAt: active}, signature, true},
+		{"unsigned", database.Feed{WebsubSecret: secret, WebsubLeaseExpiresAt: active}, "", false},
+		{"no subscription", database.Feed{}, "", false},
+		{"not verified", database.Feed{WebsubSecret: secret}, signature, false},
+		{"expired", database.Feed{WebsubSecret: secret, WebsubLeaseExpiresAt: expired}, signature, false},
+	}
+
+	for _, c := range cases {
+		valid := ValidWebSubPush(c.feed, c.signature, body, now)
+		if valid != c.expected {
+			t.Fatalf("%s: expected %v got %v", c.name, c.expected, valid)
+		}
+	}
+}
+
+func TestWebSubCallbackToken(t *testing.T) {
+	feed := database.Feed{
+		ID:           uuid.New(),
+		WebsubSecret: sql.NullString{String: "secret", Valid: true},
+	}
+
+	callback, err := url.Parse(webSubCallback("https://gorss.example.com/v1/websub/", feed.ID, "secret"))
+	if err != nil {
+		t.Fatalf("Invalid callback: %v", err)
+	}
+
+	if callback.Path != "/v1/websub/"+feed.ID.String() {
+		t.Fatalf("Invalid callback path: %s", callback.Path)
+	}
+
+	token := callback.Query().Get("token")
+	if !ValidWebSubCallbackToken(feed, token) {
+		t.Fatalf("Expected callback token to be valid")
+	}
+
+	if ValidWebSubCallbackToken(feed, "") || ValidWebSubCallbackToken(database.Feed{ID: feed.ID}, token) {
+		t.Fatalf("Expected missing token or secret to be invalid")
+	}
+
+	feed.WebsubSecret.String = "new secret"
+	if ValidWebSubCallbackToken(feed, token) {
+		t.Fatalf("Expected token of a previous secret to be invalid")
+	}
+}
+
+func TestParseXMLWebSubLinks(t *testing.T) {
+	feedData, err := DefaultParserRegistry().Parse("application/rss+xml", []byte(`<rss xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+	<title>Hub feed</title>
+	<link>https://example.com/</link>
+	<atom:link rel="hub" href="https://hub.example.com/"/>
+	<atom:link rel="self" href="https://example.com/feed.xml"/>
+</channel>
+</rss>`))
+	if err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	if feedData.HubURL != "https://hub.example.com/" || feedData.SelfURL != "https://example.com/feed.xml" {
+		t.Fatalf("Invalid WebSub links: got hub '%s' self '%s'", feedData.HubURL, feedData.SelfURL)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/PFrek/gorss/internal/api"
@@ -14,10 +15,40 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// Run modes, chosen by the first argument. Defaults to all.
+const (
+	modeServe  = "serve"  // Only the HTTP API, e.g. several replicas behind a load balancer
+	modeScrape = "scrape" // Only the scraper, with a status listener
+	modeAll    = "all"    // Both in one process
+)
+
 func main() {
+	mode := modeAll
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+	if mode != modeServe && mode != modeScrape && mode != modeAll {
+		log.Fatalf("Unknown mode %q, expected %s, %s or %s\n", mode, modeServe, modeScrape, modeAll)
+	}
+
 	godotenv.Load()
+	host := os.Getenv("HOST") // e.g. 0.0.0.0 behind a load balancer
+	if host == "" {
+		host = "localhost"
+	}
 	port := os.Getenv("PORT")
+	statusPort := os.Getenv("SCRAPER_STATUS_PORT") // Defaults to PORT in scrape mode
 	dbUrl := os.Getenv("CONNECTION")
+	maxFailures, _ := strconv.Atoi(os.Getenv("SCRAPER_MAX_FAILURES"))
+	webSubCallbackURL := os.Getenv("WEBSUB_CALLBACK_URL") // e.g. https://gorss.example.com/v1/websub
+	maxBodySize, _ := strconv.ParseInt(os.Getenv("SCRAPER_MAX_BODY_SIZE"), 10, 64)
+	connectTimeout, _ := time.ParseDuration(os.Getenv("SCRAPER_CONNECT_TIMEOUT")) // e.g. 10s
+	readTimeout, _ := time.ParseDuration(os.Getenv("SCRAPER_READ_TIMEOUT"))       // e.g. 30s
+	workers, _ := strconv.Atoi(os.Getenv("SCRAPER_WORKERS"))
+	hostConcurrency, _ := strconv.Atoi(os.Getenv("SCRAPER_HOST_CONCURRENCY"))
+	hostDelay, _ := time.ParseDuration(os.Getenv("SCRAPER_HOST_DELAY")) // e.g. 2s
+	respectRobots, _ := strconv.ParseBool(os.Getenv("SCRAPER_RESPECT_ROBOTS"))
+	claimTimeout, _ := time.ParseDuration(os.Getenv("SCRAPER_CLAIM_TIMEOUT")) // e.g. 10m
 
 	db, err := sql.Open("postgres", dbUrl)
 	if err != nil {
@@ -26,41 +57,110 @@ func main() {
 
 	dbQueries := database.New(db)
 
-	apiConfig := api.ApiConfig{
-		DB: dbQueries,
+	// Scraper
+	fetcher, err := scraper.NewHTTPFetcher(scraper.FetcherConfig{
+		ConnectTimeout: connectTimeout,
+		ReadTimeout:    readTimeout,
+		MaxBodySize:    maxBodySize,
+		UserAgent:      os.Getenv("SCRAPER_USER_AGENT"),
+		ProxyURL:       os.Getenv("SCRAPER_PROXY_URL"),
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// Scraper
 	scraper := scraper.Scraper{
-		Config:        apiConfig,
-		Cache:         make(map[string]scraper.CachedFeed),
-		CacheInterval: 30 * time.Minute,
+		DB:                dbQueries,
+		Conn:              db,
+		Fetcher:           fetcher,
+		MaxFailures:       maxFailures,
+		WebSubCallbackURL: webSubCallbackURL,
+		Workers:           workers,
+		HostConcurrency:   hostConcurrency,
+		HostDelay:         hostDelay,
+		RespectRobots:     respectRobots,
+		ClaimTimeout:      claimTimeout,
+	}
+
+	apiConfig := api.ApiConfig{
+		DB:      dbQueries,
+		Scraper: &scraper,
+	}
+
+	if mode == modeServe {
+		serveAPI(&apiConfig, host+":"+port, false)
+		return
 	}
+
 	scraper.Start(60*time.Second, 10)
 
+	if mode == modeScrape {
+		if statusPort == "" {
+			statusPort = port
+		}
+		serveScraperStatus(&apiConfig, host+":"+statusPort)
+		return
+	}
+
+	if statusPort != "" {
+		go serveScraperStatus(&apiConfig, host+":"+statusPort)
+	}
+	serveAPI(&apiConfig, host+":"+port, true)
+}
+
+// serveAPI serves the HTTP API on addr. The API still needs a scraper for
+// discovery and WebSub, but only serves its status when it runs in-process.
+func serveAPI(apiConfig *api.ApiConfig, addr string, withScraper bool) {
 	mux := http.NewServeMux()
 
 	server := http.Server{
-		Addr:    "localhost:" + port,
+		Addr:    addr,
 		Handler: mux,
 	}
 
 	mux.HandleFunc("GET /v1/healthz", api.GetHealthzHandler)
 	mux.HandleFunc("GET /v1/err", api.GetErrorHandler)
+	if withScraper {
+		mux.HandleFunc("GET /v1/scraper/status", apiConfig.GetScraperStatusHandler)
+	}
 
 	mux.HandleFunc("POST /v1/users", apiConfig.PostUsersHandler)
 	mux.HandleFunc("GET /v1/users", apiConfig.MiddleWareAuth(apiConfig.GetCurrentUserHandler))
 
 	mux.HandleFunc("POST /v1/feeds", apiConfig.MiddleWareAuth(apiConfig.PostFeedsHandler))
 	mux.HandleFunc("GET /v1/feeds", apiConfig.GetFeedsHandler)
+	mux.HandleFunc("GET /v1/feeds/{feedID}/history", apiConfig.GetFeedHistoryHandler)
+
+	mux.HandleFunc("GET /v1/websub/{feedID}", apiConfig.GetWebSubCallbackHandler)
+	mux.HandleFunc("POST /v1/websub/{feedID}", apiConfig.PostWebSubCallbackHandler)
 
 	mux.HandleFunc("POST /v1/feed_follows", apiConfig.MiddleWareAuth(apiConfig.PostFeedFollowsHandler))
 	mux.HandleFunc("DELETE /v1/feed_follows/{feedFollowID}", apiConfig.MiddleWareAuth(apiConfig.DeleteFeedFollowHandler))
 	mux.HandleFunc("GET /v1/feed_follows", apiConfig.MiddleWareAuth(apiConfig.GetFeedFollowsHandler))
 
 	mux.HandleFunc("GET /v1/posts", apiConfig.MiddleWareAuth(apiConfig.GetPostsHandler))
+	mux.HandleFunc("GET /v1/posts/{postID}/revisions", apiConfig.MiddleWareAuth(apiConfig.GetPostRevisionsHandler))
+
+	mux.HandleFunc("GET /v1/episodes", apiConfig.MiddleWareAuth(apiConfig.GetEpisodesHandler))
+	mux.HandleFunc("PUT /v1/episodes/{enclosureID}/position", apiConfig.MiddleWareAuth(apiConfig.PutPlaybackPositionHandler))
+
+	log.Printf("Starting server on %s\n", addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+// serveScraperStatus serves the scraper's status on addr, for health checks
+// of dedicated scraper processes.
+func serveScraperStatus(apiConfig *api.ApiConfig, addr string) {
+	mux := http.NewServeMux()
+
+	server := http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
 
-	log.Printf("Starting server on port %s\n", port)
-	server.ListenAndServe()
+	mux.HandleFunc("GET /v1/healthz", apiConfig.GetScraperStatusHandler)
+	mux.HandleFunc("GET /v1/scraper/status", apiConfig.GetScraperStatusHandler)
 
+	log.Printf("Starting scraper status server on %s\n", addr)
+	log.Fatal(server.ListenAndServe())
 }
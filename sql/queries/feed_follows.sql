@@ -11,3 +11,10 @@ SELECT * FROM feed_follows WHERE user_id = $1;
 
 -- name: GetFeedFollow :one
 SELECT * FROM feed_follows WHERE id = $1;
+
+-- name: MoveFeedFollows :exec
+UPDATE feed_follows
+SET feed_id = sqlc.arg(to_feed_id),
+updated_at = TIMEZONE('utc', NOW())
+WHERE feed_id = sqlc.arg(from_feed_id)
+AND user_id NOT IN (SELECT user_id FROM feed_follows WHERE feed_id = sqlc.arg(to_feed_id));
@@ -10,14 +10,97 @@ SELECT * FROM feeds;
 SELECT * FROM feeds
 WHERE id = $1;
 
--- name: GetNextFeedsToFetch :many
-SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT $1;
+-- name: ClaimNextFeedsToFetch :many
+UPDATE feeds
+SET claimed_until = sqlc.arg(claimed_until)
+WHERE id IN (
+  SELECT id FROM feeds
+  WHERE NOT dead
+  AND (next_fetch_at IS NULL OR next_fetch_at <= TIMEZONE('utc', NOW()))
+  AND (websub_lease_expires_at IS NULL OR websub_lease_expires_at <= TIMEZONE('utc', NOW()) + INTERVAL '1 day')
+  AND (claimed_until IS NULL OR claimed_until <= TIMEZONE('utc', NOW()))
+  ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST
+  LIMIT sqlc.arg(max_feeds)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ExtendFeedClaim :exec
+UPDATE feeds
+SET claimed_until = $2
+WHERE id = $1;
+
+-- name: ReleaseFeedClaim :exec
+UPDATE feeds
+SET claimed_until = NULL
+WHERE id = $1;
 
 -- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = TIMEZONE('utc', NOW()),
+failure_count = 0,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateFeedValidators :exec
+UPDATE feeds
+SET etag = $2,
+last_modified = $3
+WHERE id = $1;
+
+-- name: ClearFeedLegacyGuids :exec
+UPDATE feeds
+SET legacy_guids = FALSE
+WHERE id = $1;
+
+-- name: SetFeedNextFetch :exec
+UPDATE feeds
+SET next_fetch_at = $2
+WHERE id = $1;
+
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET failure_count = failure_count + 1,
+last_error = sqlc.arg(last_error),
+last_error_at = TIMEZONE('utc', NOW()),
+next_fetch_at = sqlc.arg(next_fetch_at),
+dead = failure_count + 1 >= sqlc.arg(max_failures)::integer,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RecordFeedSkipped :one
+UPDATE feeds
+SET last_error = sqlc.arg(last_error),
+last_error_at = TIMEZONE('utc', NOW()),
+next_fetch_at = sqlc.arg(next_fetch_at),
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetFeedByUrl :one
+SELECT * FROM feeds
+WHERE url = $1;
+
+-- name: UpdateFeedUrl :one
+UPDATE feeds
+SET url = $2,
 updated_at = TIMEZONE('utc', NOW())
 WHERE id = $1
 RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+-- name: SetFeedWebSubSubscription :exec
+UPDATE feeds
+SET websub_hub = $2,
+websub_topic = $3,
+websub_secret = $4
+WHERE id = $1;
+
+-- name: SetFeedWebSubLease :exec
+UPDATE feeds
+SET websub_lease_expires_at = $2
+WHERE id = $1;
@@ -0,0 +1,15 @@
+-- name: CreateFetchAttempt :one
+INSERT INTO fetch_attempts (id, feed_id, attempted_at, status_code, duration_ms, bytes, items_found, items_inserted, error, recoveries)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetFetchAttempts :many
+SELECT * FROM fetch_attempts
+WHERE feed_id = $1
+ORDER BY attempted_at DESC
+LIMIT $2;
+
+-- name: MoveFetchAttempts :exec
+UPDATE fetch_attempts
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
@@ -0,0 +1,10 @@
+-- name: DeferHost :exec
+INSERT INTO host_deferrals (host, deferred_until)
+VALUES ($1, $2)
+ON CONFLICT (host) DO UPDATE
+SET deferred_until = GREATEST(host_deferrals.deferred_until, EXCLUDED.deferred_until);
+
+-- name: GetHostDeferrals :many
+SELECT * FROM host_deferrals
+WHERE host = ANY(sqlc.arg(hosts)::TEXT[])
+AND deferred_until > TIMEZONE('utc', NOW());
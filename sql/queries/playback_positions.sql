@@ -0,0 +1,8 @@
+-- name: UpsertPlaybackPosition :one
+INSERT INTO playback_positions (user_id, enclosure_id, updated_at, position_seconds, completed)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, enclosure_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+position_seconds = EXCLUDED.position_seconds,
+completed = EXCLUDED.completed
+RETURNING *;
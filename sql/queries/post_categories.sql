@@ -0,0 +1,12 @@
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePostCategories :exec
+DELETE FROM post_categories WHERE post_id = $1;
+
+-- name: GetCategoriesForPosts :many
+SELECT * FROM post_categories
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[])
+ORDER BY name;
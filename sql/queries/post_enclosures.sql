@@ -0,0 +1,30 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (id, post_id, url, type, length)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetEnclosuresForPosts :many
+SELECT * FROM post_enclosures
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[]);
+
+-- name: GetPostEnclosureByUser :one
+SELECT post_enclosures.* FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE post_enclosures.id = $1
+AND posts.feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = $2);
+
+-- name: GetEpisodesByUser :many
+SELECT post_enclosures.id AS enclosure_id, post_enclosures.url, post_enclosures.type, post_enclosures.length,
+posts.id AS post_id, posts.title, posts.published_at, posts.feed_id, posts.duration_seconds,
+posts.episode, posts.season, posts.image_url, posts.explicit,
+playback_positions.position_seconds, playback_positions.completed
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+LEFT JOIN playback_positions ON playback_positions.enclosure_id = post_enclosures.id
+	AND playback_positions.user_id = $1
+WHERE posts.feed_id IN (SELECT feed_id FROM feed_follows
+	WHERE user_id = $1
+)
+AND (post_enclosures.type LIKE 'audio%' OR post_enclosures.type LIKE 'video%')
+ORDER BY posts.published_at DESC
+LIMIT $2;
@@ -0,0 +1,9 @@
+-- name: CreatePostRevision :one
+INSERT INTO post_revisions (id, created_at, post_id, title, url, description, published_at, content_hash, content)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY created_at DESC;
@@ -1,6 +1,6 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid, content_hash, content, author, comments_url, duration_seconds, episode, season, image_url, explicit, description_text, content_text, content_hash_version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 RETURNING *;
 
 -- name: GetPostsByUser :many
@@ -10,3 +10,52 @@ WHERE feed_id IN (SELECT feed_id FROM feed_follows
 )
 ORDER BY published_at DESC
 LIMIT $2;
+
+-- name: MovePosts :exec
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id),
+updated_at = TIMEZONE('utc', NOW())
+WHERE feed_id = sqlc.arg(from_feed_id)
+AND guid NOT IN (SELECT guid FROM posts WHERE feed_id = sqlc.arg(to_feed_id));
+
+-- name: CountFeedPosts :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1;
+
+-- name: GetPostByUser :one
+SELECT * FROM posts
+WHERE id = $1
+AND feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = $2);
+
+-- name: GetPostByGuid :one
+SELECT * FROM posts
+WHERE feed_id = $1 AND guid = $2;
+
+-- name: AdoptLegacyPostGuid :exec
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE feed_id = sqlc.arg(feed_id)
+AND guid = sqlc.arg(legacy_guid)
+AND NOT EXISTS (SELECT 1 FROM posts WHERE feed_id = sqlc.arg(feed_id) AND guid = sqlc.arg(guid));
+
+-- name: UpdatePostContent :one
+UPDATE posts
+SET title = $2,
+url = $3,
+description = $4,
+published_at = $5,
+content_hash = $6,
+content = $7,
+author = $8,
+comments_url = $9,
+duration_seconds = $10,
+episode = $11,
+season = $12,
+image_url = $13,
+explicit = $14,
+description_text = $15,
+content_text = $16,
+content_hash_version = $17,
+updated_at = TIMEZONE('utc', NOW())
+WHERE id = $1
+RETURNING *;
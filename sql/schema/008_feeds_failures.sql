@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT,
+ADD COLUMN last_error_at TIMESTAMP,
+ADD COLUMN dead BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN failure_count,
+DROP COLUMN last_error,
+DROP COLUMN last_error_at,
+DROP COLUMN dead;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE fetch_attempts (
+	id UUID PRIMARY KEY,
+	feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+	attempted_at TIMESTAMP NOT NULL,
+	status_code INTEGER,
+	duration_ms INTEGER NOT NULL,
+	bytes INTEGER NOT NULL,
+	items_found INTEGER NOT NULL,
+	items_inserted INTEGER NOT NULL,
+	error TEXT
+);
+
+CREATE INDEX fetch_attempts_feed_id_attempted_at_idx ON fetch_attempts (feed_id, attempted_at DESC);
+
+-- +goose Down
+DROP TABLE fetch_attempts;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN websub_hub TEXT,
+ADD COLUMN websub_topic TEXT,
+ADD COLUMN websub_secret TEXT,
+ADD COLUMN websub_lease_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN websub_hub,
+DROP COLUMN websub_topic,
+DROP COLUMN websub_secret,
+DROP COLUMN websub_lease_expires_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN guid TEXT,
+ADD COLUMN content_hash TEXT;
+
+UPDATE posts
+SET guid = url,
+content_hash = encode(sha256(convert_to(title || E'\n' || url || E'\n' || COALESCE(description, ''), 'UTF8')), 'hex');
+
+ALTER TABLE posts
+ALTER COLUMN guid SET NOT NULL,
+ALTER COLUMN content_hash SET NOT NULL,
+DROP CONSTRAINT posts_url_key,
+ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts
+DROP CONSTRAINT posts_feed_id_guid_key,
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN guid,
+DROP COLUMN content_hash;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE post_revisions (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	title TEXT NOT NULL,
+	url TEXT NOT NULL,
+	description TEXT,
+	published_at TIMESTAMP NOT NULL,
+	content_hash TEXT NOT NULL
+);
+
+CREATE INDEX post_revisions_post_id_created_at_idx ON post_revisions (post_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE post_revisions;
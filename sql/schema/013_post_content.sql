@@ -0,0 +1,35 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT,
+ADD COLUMN author TEXT,
+ADD COLUMN comments_url TEXT;
+
+ALTER TABLE post_revisions
+ADD COLUMN content TEXT;
+
+CREATE TABLE post_categories (
+	post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	PRIMARY KEY (post_id, name)
+);
+
+CREATE TABLE post_enclosures (
+	id UUID PRIMARY KEY,
+	post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+	url TEXT NOT NULL,
+	type TEXT,
+	length BIGINT,
+	UNIQUE(post_id, url)
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
+DROP TABLE post_categories;
+
+ALTER TABLE post_revisions
+DROP COLUMN content;
+
+ALTER TABLE posts
+DROP COLUMN content,
+DROP COLUMN author,
+DROP COLUMN comments_url;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN duration_seconds INTEGER,
+ADD COLUMN episode INTEGER,
+ADD COLUMN season INTEGER,
+ADD COLUMN image_url TEXT,
+ADD COLUMN explicit BOOLEAN;
+
+CREATE TABLE playback_positions (
+	user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	enclosure_id UUID NOT NULL REFERENCES post_enclosures(id) ON DELETE CASCADE,
+	updated_at TIMESTAMP NOT NULL,
+	position_seconds INTEGER NOT NULL,
+	completed BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (user_id, enclosure_id)
+);
+
+-- +goose Down
+DROP TABLE playback_positions;
+
+ALTER TABLE posts
+DROP COLUMN duration_seconds,
+DROP COLUMN episode,
+DROP COLUMN season,
+DROP COLUMN image_url,
+DROP COLUMN explicit;
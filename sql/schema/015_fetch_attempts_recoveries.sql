@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE fetch_attempts
+ADD COLUMN recoveries TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE fetch_attempts
+DROP COLUMN recoveries;
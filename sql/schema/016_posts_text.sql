@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN description_text TEXT,
+ADD COLUMN content_text TEXT;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN description_text,
+DROP COLUMN content_text;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Scheme the content hash was computed with. Existing posts were hashed
+-- before full content was captured.
+ALTER TABLE posts
+ADD COLUMN content_hash_version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE posts
+ALTER COLUMN content_hash_version DROP DEFAULT;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN content_hash_version;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE host_deferrals (
+	host TEXT PRIMARY KEY,
+	deferred_until TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE host_deferrals;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN legacy_guids BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Feeds that may have posts stored before GUIDs were tracked, which
+-- migration 011 gave their link as GUID. The publisher's GUIDs are adopted
+-- the next time they are fetched.
+UPDATE feeds
+SET legacy_guids = TRUE
+WHERE EXISTS (SELECT 1 FROM posts WHERE posts.feed_id = feeds.id AND posts.guid = posts.url);
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN legacy_guids;